@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoActiveReceivers 开启 OnlyActive 后接收人中没有已激活的成员，未发送消息
+var ErrNoActiveReceivers = errors.New("no active receivers")
+
+// userStatusActive 成员激活状态：已激活
+const userStatusActive = 1
+
+const (
+	// activeQueryConcurrency ActiveUsers 并发查询成员状态的请求数
+	activeQueryConcurrency = 4
+	// activeStatusTTL 成员激活状态的缓存时长
+	activeStatusTTL = 10 * time.Minute
+)
+
+// activeCache 成员激活状态的缓存，避免 OnlyActive 每次发送都逐个查询
+type activeCache struct {
+	mu    sync.Mutex
+	items map[string]activeStatus
+}
+
+type activeStatus struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// ActiveUsers 返回 userIDs 中已激活企业微信的成员，按 userIDs 的顺序排列。
+// 企业微信没有在线状态接口，此处以成员的激活状态（user/get 返回的 status 为 1）判断，
+// 未缓存的成员每人请求一次接口，最多 4 个并发，结果缓存 10 分钟
+func (n *Notify) ActiveUsers(userIDs []string) ([]string, error) {
+	now := n.now()
+	status := make(map[string]bool, len(userIDs))
+	var missing []string
+	n.active.mu.Lock()
+	for _, id := range userIDs {
+		if _, ok := status[id]; ok {
+			continue
+		}
+		if cached, ok := n.active.items[id]; ok && now.Before(cached.expiresAt) {
+			status[id] = cached.active
+			continue
+		}
+		status[id] = false
+		missing = append(missing, id)
+	}
+	n.active.mu.Unlock()
+
+	if len(missing) > 0 {
+		fetched, err := n.fetchActive(missing)
+		if err != nil {
+			return nil, err
+		}
+		n.active.mu.Lock()
+		if n.active.items == nil {
+			n.active.items = make(map[string]activeStatus)
+		}
+		for i, id := range missing {
+			status[id] = fetched[i]
+			n.active.items[id] = activeStatus{active: fetched[i], expiresAt: now.Add(activeStatusTTL)}
+		}
+		n.active.mu.Unlock()
+	}
+
+	active := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if status[id] {
+			active = append(active, id)
+		}
+	}
+	return active, nil
+}
+
+// fetchActive 并发查询成员的激活状态，任一查询失败时返回错误
+func (n *Notify) fetchActive(userIDs []string) ([]bool, error) {
+	// 预先获取 token，避免并发查询时各自刷新
+	if _, _, err := n.GetToken(); err != nil {
+		return nil, err
+	}
+
+	active := make([]bool, len(userIDs))
+	errs := make([]error, len(userIDs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, activeQueryConcurrency)
+	for i := range userIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var result struct {
+				Status int `json:"status"`
+			}
+			query := url.Values{}
+			query.Set("userid", userIDs[i])
+			errs[i] = n.callAPI(http.MethodGet, "user/get", query, nil, &result)
+			active[i] = result.Status == userStatusActive
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return active, nil
+}
+
+// filterActive 将 receiver 的成员列表过滤为已激活的成员，查询失败时尽力而为地返回原接收人。
+// @all 及部门、标签接收人不做过滤
+func (n *Notify) filterActive(receiver MessageReceiver) (MessageReceiver, error) {
+	if receiver.ToUser == "" || receiver.ToUser == "@all" {
+		return receiver, nil
+	}
+	active, err := n.ActiveUsers(strings.Split(receiver.ToUser, "|"))
+	if err != nil {
+		return receiver, nil
+	}
+	receiver.ToUser = strings.Join(active, "|")
+	if receiver.ToUser == "" && receiver.ToParty == "" && receiver.ToTag == "" {
+		return receiver, ErrNoActiveReceivers
+	}
+	return receiver, nil
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_SendOnlyActive(t *testing.T) {
+	var sent string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/get":
+			if r.URL.Query().Get("userid") == "broken" {
+				_, _ = w.Write([]byte(`{"errcode":60111,"errmsg":"userid not found"}`))
+				return
+			}
+			status := "4"
+			if strings.HasPrefix(r.URL.Query().Get("userid"), "on") {
+				status = "1"
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","status":` + status + `}`))
+		case "/message/send":
+			b, _ := io.ReadAll(r.Body)
+			sent = string(b)
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	})
+	options := &MessageOptions{OnlyActive: true}
+
+	if _, err := n.Send(MessageReceiver{ToUser: "on1|off|on2"}, Text{Content: "hi"}, options); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if !strings.Contains(sent, `"touser":"on1|on2"`) {
+		t.Errorf("Send() body = %s, want touser on1|on2", sent)
+	}
+
+	if _, err := n.Send(MessageReceiver{ToUser: "off"}, Text{Content: "hi"}, options); err != ErrNoActiveReceivers {
+		t.Errorf("Send() error = %v, want %v", err, ErrNoActiveReceivers)
+	}
+
+	// 查询失败时发送给全部接收人
+	if _, err := n.Send(MessageReceiver{ToUser: "off|broken"}, Text{Content: "hi"}, options); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if !strings.Contains(sent, `"touser":"off|broken"`) {
+		t.Errorf("Send() body = %s, want touser off|broken", sent)
+	}
+}
+
+func TestNotify_ActiveUsersCached(t *testing.T) {
+	var queries, inflight, peak int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&queries, 1)
+		cur := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if cur <= p || atomic.CompareAndSwapInt32(&peak, p, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","status":1}`))
+	})
+	now := time.Now()
+	n.now = func() time.Time { return now }
+
+	ids := make([]string, 50)
+	for i := range ids {
+		ids[i] = "u" + strconv.Itoa(i)
+	}
+	ids = append(ids, "u0")
+	for i := 0; i < 2; i++ {
+		active, err := n.ActiveUsers(ids)
+		if err != nil || len(active) != len(ids) {
+			t.Fatalf("ActiveUsers() = %d users, %v, want all %d active", len(active), err, len(ids))
+		}
+	}
+	if got := atomic.LoadInt32(&queries); got != 50 {
+		t.Errorf("user/get requests = %d, want each user queried once", got)
+	}
+	if got := atomic.LoadInt32(&peak); got > activeQueryConcurrency {
+		t.Errorf("concurrent requests = %d, want at most %d", got, activeQueryConcurrency)
+	}
+
+	now = now.Add(activeStatusTTL)
+	if _, err := n.ActiveUsers([]string{"u1"}); err != nil {
+		t.Fatalf("ActiveUsers() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&queries); got != 51 {
+		t.Errorf("user/get requests = %d, want expired status queried again", got)
+	}
+}
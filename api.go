@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// apiBusyRetries DoAPI 在系统繁忙时的最大重试次数
+	apiBusyRetries = 2
+	// apiRetryBackoff DoAPI 第一次重试前的等待时长，之后每次翻倍
+	apiRetryBackoff = 200 * time.Millisecond
+)
+
+// DoAPI 调用任意企业微信服务端接口，用于尚未封装的通讯录、审批等接口。path 为 cgi-bin 之后的路径，可包含查询参数，
+// 如 "user/get?userid=zhangsan"，access_token 自动添加，agentid 等其他参数需由调用方设置。
+// body 不为 nil 时以 JSON 编码作为请求体，返回内容解析到 out，out 为 nil 时不解析。
+// access_token 失效时刷新后重试（次数同 SetTokenRetries），系统繁忙时等待后重试，请求失败时不重试；
+// 错误码不为 0 时返回 *APIError 或 IPNotAllowedError 等具体错误类型，可通过 errors.As 获取错误码
+func (n *Notify) DoAPI(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return errors.New("api path can not be empty")
+	}
+	var query url.Values
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		var err error
+		if query, err = url.ParseQuery(path[i+1:]); err != nil {
+			return fmt.Errorf("%s parse query error: %w", path, err)
+		}
+		path = path[:i]
+	}
+
+	tokenRetries := n.tokenRetriesFor(ctx)
+	backoff := apiRetryBackoff
+	for tokenRetry, busyRetry := 0, 0; ; {
+		token, _, err := n.GetTokenContext(ctx)
+		if err != nil {
+			return err
+		}
+		err = n.callAPIWithToken(ctx, token, method, path, query, body, out)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			return err
+		}
+		switch {
+		case isTokenInvalid(apiErr.Code) && tokenRetry < tokenRetries:
+			tokenRetry++
+			n.invalidateToken(token)
+		case apiErr.Code == errCodeSystemBusy && busyRetry < apiBusyRetries:
+			busyRetry++
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestNotify_DoAPI(t *testing.T) {
+	var calls int
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/user/get":
+			if r.URL.Query().Get("userid") != "zhangsan" || r.URL.Query().Get("access_token") != "token" {
+				t.Errorf("request query = %s, want userid and access_token", r.URL.RawQuery)
+			}
+			switch calls {
+			case 1:
+				_, _ = w.Write([]byte(`{"errcode":42001,"errmsg":"access_token expired"}`))
+			case 2:
+				_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			default:
+				_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","name":"张三"}`))
+			}
+		case "/user/create":
+			if b, _ := io.ReadAll(r.Body); string(b) != `{"userid":"lisi"}` {
+				t.Errorf("request body = %s", b)
+			}
+			_, _ = w.Write([]byte(`{"errcode":60102,"errmsg":"userid existed"}`))
+		}
+	})
+
+	var user struct {
+		Name string `json:"name"`
+	}
+	if err := n.DoAPI(context.Background(), http.MethodGet, "/user/get?userid=zhangsan", nil, &user); err != nil {
+		t.Fatalf("DoAPI() error = %v, want no error", err)
+	}
+	if user.Name != "张三" || calls != 3 {
+		t.Errorf("DoAPI() name = %q, calls = %d, want 张三 after token and busy retries", user.Name, calls)
+	}
+
+	err := n.DoAPI(context.Background(), http.MethodPost, "user/create", map[string]string{"userid": "lisi"}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 60102 {
+		t.Errorf("DoAPI() error = %v, want APIError 60102", err)
+	}
+}
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrRetryBudgetExhausted 批量发送的重试预算已用完，失败的任务不再重试
+var ErrRetryBudgetExhausted = errors.New("batch retry budget exhausted")
+
+// RetryBudgetError 重试预算已用完，Err 为最后一次发送的错误。
+// errors.Is 可判断 ErrRetryBudgetExhausted，errors.As 可取得 Err 中的 APIError 等错误
+type RetryBudgetError struct {
+	Err error
+}
+
+func (e *RetryBudgetError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrRetryBudgetExhausted, e.Err)
+}
+
+// Is 判断是否为 ErrRetryBudgetExhausted
+func (e *RetryBudgetError) Is(target error) bool {
+	return target == ErrRetryBudgetExhausted
+}
+
+// Unwrap 返回最后一次发送的错误
+func (e *RetryBudgetError) Unwrap() error {
+	return e.Err
+}
+
+// BatchJob 批量发送中的单条消息
+type BatchJob struct {
+	Receiver MessageReceiver
+	Message  interface{}
+	Options  *MessageOptions
+}
+
+// BatchResult 批量发送中单条消息的发送结果，顺序与 BatchJob 一致
+type BatchResult struct {
+	Result   MessageResult
+	Err      error
+	Attempts int // 调用发送的次数，1 表示未重试，请求前 ctx 已取消时为 0
+}
+
+// BatchConfig 批量发送配置
+type BatchConfig struct {
+	Concurrency   int           // 并发数，默认 1
+	Retries       int           // 单条消息请求失败或系统繁忙时的最大重试次数，默认不重试
+	RetryBudget   int           // 整个批次共享的重试次数上限，避免企业微信故障期间大量重试，0 表示不限制
+	RetryInterval time.Duration // 重试间隔
+	RetryIf       RetryFunc     // 判断是否重试，默认为 DefaultRetryIf
+
+	// Progress 非必填。每条消息发送完成（包括重试）后写入进度，批次结束后关闭。
+	// 写入会阻塞发送，调用方需持续读取或使用足够大的缓冲
+	Progress chan<- BatchProgress
+}
+
+// BatchProgress 批量发送中单条消息的完成进度
+type BatchProgress struct {
+	Index int // 消息在 jobs 中的序号
+	BatchResult
+}
+
+// RetryFunc 根据发送结果判断是否重试，httpStatus 为发送消息返回的 HTTP 状态码，请求失败时为 0
+type RetryFunc func(result MessageResult, httpStatus int, err error) bool
+
+// DefaultRetryIf 默认的重试判断，请求失败、返回内容无法解析（如网关返回 502 错误页）或系统繁忙时重试，
+// 参数校验、接口返回的其他错误码及调用方取消或超时不重试。自定义的 RetryFunc 可在特殊情况之外调用它
+func DefaultRetryIf(result MessageResult, httpStatus int, err error) bool {
+	return isRetryable(result, err)
+}
+
+// responseStatus 返回发送结果对应的 HTTP 状态码，请求失败或无法确定时为 0
+func responseStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return decodeErr.StatusCode
+	}
+	return 0
+}
+
+// SendBatch 按配置并发发送多条消息，返回每条消息的发送结果
+func (n *Notify) SendBatch(jobs []BatchJob, config BatchConfig) []BatchResult {
+	return n.SendBatchContext(context.Background(), jobs, config)
+}
+
+// SendBatchContext 同 SendBatch，ctx 取消后未开始的消息返回 ctx 的错误
+func (n *Notify) SendBatchContext(ctx context.Context, jobs []BatchJob, config BatchConfig) []BatchResult {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	budget := &retryBudget{unlimited: config.RetryBudget <= 0, remaining: config.RetryBudget}
+	results := make([]BatchResult, len(jobs))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = n.sendJob(ctx, jobs[i], config, budget)
+			if config.Progress != nil {
+				config.Progress <- BatchProgress{Index: i, BatchResult: results[i]}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if config.Progress != nil {
+		close(config.Progress)
+	}
+	return results
+}
+
+// sendJob 发送单条消息，失败时在重试次数及批次预算内重试
+func (n *Notify) sendJob(ctx context.Context, job BatchJob, config BatchConfig, budget *retryBudget) BatchResult {
+	retryIf := config.RetryIf
+	if retryIf == nil {
+		retryIf = DefaultRetryIf
+	}
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return BatchResult{Err: err, Attempts: attempt}
+		}
+		result, err := n.SendContext(ctx, job.Receiver, job.Message, job.Options)
+		// ctx 已结束时重试必然失败，不再占用重试预算
+		if attempt >= config.Retries || ctx.Err() != nil || !retryIf(result, responseStatus(err), err) {
+			return BatchResult{Result: result, Err: err, Attempts: attempt + 1}
+		}
+		if !budget.take() {
+			if err == nil {
+				err = newAPIError(result.ErrorCode, result.ErrorMsg)
+			}
+			return BatchResult{Result: result, Err: &RetryBudgetError{Err: err}, Attempts: attempt + 1}
+		}
+		if config.RetryInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return BatchResult{Result: result, Err: ctx.Err(), Attempts: attempt + 1}
+			case <-time.After(config.RetryInterval):
+			}
+		}
+	}
+}
+
+// retryBudget 批次共享的重试预算
+type retryBudget struct {
+	mu        sync.Mutex
+	unlimited bool
+	remaining int
+}
+
+// take 占用一次重试，预算不足时返回 false
+func (b *retryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.unlimited {
+		return true
+	}
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// isRetryable 判断发送失败是否为请求失败、返回内容无法解析或系统繁忙等可重试的错误，
+// 参数校验、接口返回的其他错误码、调用方取消及超时（含 TTL 及 HTTP 客户端超时）不重试
+func isRetryable(result MessageResult, err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return isTransient(result, err)
+}
+
+// isTransient 判断发送失败是否为请求失败、返回内容无法解析或系统繁忙等服务端的临时错误，
+// 请求超时视为服务端的临时错误，计入熔断失败次数，调用方取消不计入
+func isTransient(result MessageResult, err error) bool {
+	if err != nil {
+		var urlErr *url.Error
+		var decodeErr *DecodeError
+		if errors.Is(err, context.Canceled) {
+			return false
+		}
+		return errors.As(err, &urlErr) || errors.As(err, &decodeErr)
+	}
+	return result.ErrorCode == errCodeSystemBusy
+}
@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_SendBatchRetryBudget(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+	})
+
+	jobs := make([]BatchJob, 4)
+	for i := range jobs {
+		jobs[i] = BatchJob{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}}
+	}
+	results := n.SendBatch(jobs, BatchConfig{Concurrency: 2, Retries: 3, RetryBudget: 2})
+
+	var exhausted int
+	for _, r := range results {
+		if errors.Is(r.Err, ErrRetryBudgetExhausted) {
+			exhausted++
+		}
+		var apiErr *APIError
+		if !errors.As(r.Err, &apiErr) || apiErr.Code != errCodeSystemBusy {
+			t.Errorf("SendBatch() error = %v, want last APIError kept", r.Err)
+		}
+	}
+	// 4 次首次发送加 2 次重试
+	if got := atomic.LoadInt32(&sends); got != 6 {
+		t.Errorf("sends = %d, want 6", got)
+	}
+	if exhausted != 4 {
+		t.Errorf("budget exhausted results = %d, want 4", exhausted)
+	}
+}
+
+func TestNotify_SendBatch(t *testing.T) {
+	var calls int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	results := n.SendBatch([]BatchJob{
+		{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}},
+		{Receiver: MessageReceiver{}, Message: Text{Content: "hi"}},
+	}, BatchConfig{Retries: 1})
+	if results[0].Err != nil || results[0].Result.ErrorCode != 0 {
+		t.Errorf("SendBatch() results[0] = %+v, want success after retry", results[0])
+	}
+	if results[1].Err == nil {
+		t.Errorf("SendBatch() results[1] want error for missing receiver")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("sends = %d, want 2", got)
+	}
+}
+
+func TestNotify_SendBatchAttempts(t *testing.T) {
+	var calls int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	jobs := []BatchJob{
+		{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}},
+		{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}},
+	}
+	results := n.SendBatch(jobs, BatchConfig{Retries: 3})
+	for i, want := range []int{3, 1} {
+		if results[i].Err != nil || results[i].Attempts != want {
+			t.Errorf("SendBatch() results[%d] = %+v, want success on attempt %d", i, results[i], want)
+		}
+	}
+}
+
+func TestNotify_SendBatchRetryIf(t *testing.T) {
+	var calls int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>502 Bad Gateway</html>"))
+		case 2:
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+		default:
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	})
+
+	var statuses []int
+	retryIf := func(result MessageResult, httpStatus int, err error) bool {
+		statuses = append(statuses, httpStatus)
+		// 系统繁忙不重试，其他情况使用默认判断
+		if result.ErrorCode == errCodeSystemBusy {
+			return false
+		}
+		return DefaultRetryIf(result, httpStatus, err)
+	}
+	results := n.SendBatch([]BatchJob{{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}}}, BatchConfig{Retries: 3, RetryIf: retryIf})
+	if results[0].Err != nil || results[0].Result.ErrorCode != errCodeSystemBusy {
+		t.Errorf("SendBatch() = %+v, want system busy without retry", results[0])
+	}
+	if want := []int{http.StatusBadGateway, http.StatusOK}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("RetryIf() statuses = %v, want %v", statuses, want)
+	}
+}
+
+func TestNotify_SendBatchProgress(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	jobs := []BatchJob{
+		{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}},
+		{Receiver: MessageReceiver{}, Message: Text{Content: "hi"}},
+		{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}},
+	}
+	progress := make(chan BatchProgress)
+	done := make(chan map[int]error)
+	go func() {
+		got := make(map[int]error)
+		for p := range progress {
+			got[p.Index] = p.Err
+		}
+		done <- got
+	}()
+
+	n.SendBatch(jobs, BatchConfig{Concurrency: 2, Progress: progress})
+	got := <-done
+	if len(got) != 3 || got[0] != nil || got[1] == nil || got[2] != nil {
+		t.Errorf("SendBatch() progress = %v, want 3 events with error at index 1", got)
+	}
+}
+
+func TestNotify_SendBatchDeadline(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	job := BatchJob{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}, Options: &MessageOptions{TTL: 20 * time.Millisecond}}
+
+	results := n.SendBatch([]BatchJob{job}, BatchConfig{Retries: 3})
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) || results[0].Attempts != 1 {
+		t.Errorf("SendBatch() = %+v, want deadline exceeded without retry", results[0])
+	}
+	if got := atomic.LoadInt32(&sends); got != 1 {
+		t.Errorf("sends = %d, want 1", got)
+	}
+}
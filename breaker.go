@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 连续发送失败次数达到阈值，熔断期间直接返回失败
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// errCodeSystemBusy 系统繁忙
+const errCodeSystemBusy = -1
+
+// circuitBreaker 熔断器。连续失败 threshold 次后熔断 cooldown 时长，之后放行一次请求探测是否恢复
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int       // 连续失败次数
+	openedAt  time.Time // 最近一次熔断的时间
+	probing   bool      // 熔断结束后是否已放行探测请求
+}
+
+// EnableCircuitBreaker 开启熔断，连续 threshold 次请求失败或系统繁忙后 cooldown 时长内 Send 直接返回 ErrCircuitOpen，
+// 之后放行一次发送，成功则恢复，失败则再次熔断。threshold 不大于 0 时关闭熔断。MessageOptions.Bypass 为 true 的发送不受熔断限制。
+// 设置了 Store 时连续失败次数及熔断时间保存在 Store 中，同一应用的多个进程共享熔断状态
+func (n *Notify) EnableCircuitBreaker(threshold int, cooldown time.Duration) {
+	n.breaker.mu.Lock()
+	defer n.breaker.mu.Unlock()
+	n.breaker.threshold = threshold
+	n.breaker.cooldown = cooldown
+	n.breaker.failures = 0
+	n.breaker.probing = false
+}
+
+// allow 判断是否允许发送
+func (b *circuitBreaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return nil
+	}
+	if now.Before(b.openedAt.Add(b.cooldown)) || b.probing {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record 记录发送结果，可重试的错误计为失败，接口返回的其他错误码说明服务可用
+func (b *circuitBreaker) record(now time.Time, result MessageResult, err error) {
+	failed := isTransient(result, err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 {
+		return
+	}
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = now
+	}
+}
+
+// breakerStateTTL 熔断状态在 Store 中的有效期
+const breakerStateTTL = 24 * time.Hour
+
+// breakerState 保存在 Store 中的熔断状态，供多个短生命周期的进程共享
+type breakerState struct {
+	Failures int   `json:"failures"`
+	OpenedAt int64 `json:"opened_at"` // 最近一次熔断的时间，Unix 纳秒
+}
+
+// breakerAllow 判断是否允许发送，设置了 Store 时先从 Store 加载其他进程记录的熔断状态
+func (n *Notify) breakerAllow() error {
+	if n.store != nil {
+		n.loadBreakerState()
+	}
+	return n.breaker.allow(n.now())
+}
+
+// breakerRecord 记录发送结果，设置了 Store 时将熔断状态写入 Store
+func (n *Notify) breakerRecord(result MessageResult, err error) {
+	n.breaker.record(n.now(), result, err)
+	if n.store != nil {
+		n.saveBreakerState()
+	}
+}
+
+// loadBreakerState 从 Store 加载熔断状态，读取失败时使用内存中的状态
+func (n *Notify) loadBreakerState() {
+	b, err := n.store.Get(n.storeKey("breaker"))
+	if err != nil {
+		return
+	}
+	var state breakerState
+	if err = n.codec.Unmarshal(b, &state); err != nil {
+		return
+	}
+	n.breaker.mu.Lock()
+	n.breaker.failures = state.Failures
+	n.breaker.openedAt = time.Unix(0, state.OpenedAt)
+	n.breaker.mu.Unlock()
+}
+
+// saveBreakerState 将熔断状态写入 Store，未开启熔断时不写入
+func (n *Notify) saveBreakerState() {
+	n.breaker.mu.Lock()
+	if n.breaker.threshold <= 0 {
+		n.breaker.mu.Unlock()
+		return
+	}
+	state := breakerState{Failures: n.breaker.failures, OpenedAt: n.breaker.openedAt.UnixNano()}
+	n.breaker.mu.Unlock()
+
+	if b, err := n.codec.Marshal(state); err == nil {
+		_ = n.store.Set(n.storeKey("breaker"), b, breakerStateTTL)
+	}
+}
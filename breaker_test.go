@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_EnableCircuitBreaker(t *testing.T) {
+	var down int32 = 1
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	now := time.Now()
+	n.now = func() time.Time { return now }
+	n.EnableCircuitBreaker(2, time.Minute)
+
+	send := func() error {
+		_, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := send(); err != nil {
+			t.Fatalf("Send() error = %v, want no error", err)
+		}
+	}
+	if err := send(); err != ErrCircuitOpen {
+		t.Fatalf("Send() error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "page"}, &MessageOptions{Bypass: true}); err != nil {
+		t.Fatalf("Send() with Bypass error = %v, want no error", err)
+	}
+
+	// 熔断结束后放行探测请求，仍失败则再次熔断
+	now = now.Add(time.Minute)
+	if err := send(); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if err := send(); err != ErrCircuitOpen {
+		t.Fatalf("Send() error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	now = now.Add(time.Minute)
+	atomic.StoreInt32(&down, 0)
+	for i := 0; i < 3; i++ {
+		if err := send(); err != nil {
+			t.Fatalf("Send() error = %v, want no error after recovery", err)
+		}
+	}
+}
+
+func TestNotify_CircuitBreakerStore(t *testing.T) {
+	store := NewMemoryStore()
+	newNotify := func() *Notify {
+		n := New("corp", 1, "secret")
+		n.SetStore(store)
+		n.EnableCircuitBreaker(2, time.Minute)
+		n.Use(func(next SendFunc) SendFunc {
+			return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+				return MessageResult{ErrorCode: errCodeSystemBusy, ErrorMsg: "system busy"}, nil
+			}
+		})
+		return n
+	}
+
+	first := newNotify()
+	for i := 0; i < 2; i++ {
+		_, _ = first.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+	}
+
+	// 新创建的客户端从 Store 加载熔断状态
+	if _, err := newNotify().Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != ErrCircuitOpen {
+		t.Errorf("Send() error = %v, want %v", err, ErrCircuitOpen)
+	}
+}
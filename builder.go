@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MessageBuilder 以链式调用构造接收人、消息及配置并发送，如
+//
+//	notify.Message().ToUsers("a", "b").Text("hi").Safe().Send(client)
+//
+// 链式调用中的错误在 Build 或 Send 时返回
+type MessageBuilder struct {
+	receiver MessageReceiver
+	message  interface{}
+	options  MessageOptions
+	err      error
+}
+
+// Message 创建消息构造器
+func Message() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// ToUsers 追加接收成员
+func (b *MessageBuilder) ToUsers(userIDs ...string) *MessageBuilder {
+	b.receiver.ToUser = appendIDs(b.receiver.ToUser, userIDs)
+	return b
+}
+
+// ToParties 追加接收部门
+func (b *MessageBuilder) ToParties(partyIDs ...string) *MessageBuilder {
+	b.receiver.ToParty = appendIDs(b.receiver.ToParty, partyIDs)
+	return b
+}
+
+// ToTags 追加接收标签
+func (b *MessageBuilder) ToTags(tagIDs ...string) *MessageBuilder {
+	b.receiver.ToTag = appendIDs(b.receiver.ToTag, tagIDs)
+	return b
+}
+
+// ToAll 发送给应用可见范围内的全部成员
+func (b *MessageBuilder) ToAll() *MessageBuilder {
+	b.receiver.ToUser = "@all"
+	return b
+}
+
+// Receiver 设置接收人，替换之前设置的接收人
+func (b *MessageBuilder) Receiver(receiver MessageReceiver) *MessageBuilder {
+	b.receiver = receiver
+	return b
+}
+
+// appendIDs 将 ids 以‘|’追加到 list
+func appendIDs(list string, ids []string) string {
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if list != "" {
+			list += "|"
+		}
+		list += id
+	}
+	return list
+}
+
+// Text 设置文本消息
+func (b *MessageBuilder) Text(content string) *MessageBuilder {
+	return b.Body(Text{Content: content})
+}
+
+// Markdown 设置 markdown 消息
+func (b *MessageBuilder) Markdown(content string) *MessageBuilder {
+	return b.Body(Markdown{Content: content})
+}
+
+// TextCard 设置文本卡片消息
+func (b *MessageBuilder) TextCard(title, description, url string) *MessageBuilder {
+	card, err := NewTextCard(title, description, url)
+	if err != nil {
+		b.setErr(err)
+	}
+	return b.Body(card)
+}
+
+// Image 设置图片消息
+func (b *MessageBuilder) Image(mediaID string) *MessageBuilder {
+	return b.Body(Image{MediaID: mediaID})
+}
+
+// File 设置文件消息
+func (b *MessageBuilder) File(mediaID string) *MessageBuilder {
+	return b.Body(File{MediaID: mediaID})
+}
+
+// Body 设置任意类型的消息，每个构造器只能设置一次消息
+func (b *MessageBuilder) Body(message interface{}) *MessageBuilder {
+	if b.message != nil {
+		b.setErr(errors.New("message already set"))
+	}
+	b.message = message
+	return b
+}
+
+// Safe 设置为保密消息
+func (b *MessageBuilder) Safe() *MessageBuilder {
+	b.options.Safe = true
+	return b
+}
+
+// EnableIDTrans 开启 id 转译
+func (b *MessageBuilder) EnableIDTrans() *MessageBuilder {
+	b.options.EnableIDTrans = true
+	return b
+}
+
+// DuplicateCheck 开启重复消息检查，interval 为 0 时使用默认间隔
+func (b *MessageBuilder) DuplicateCheck(interval time.Duration) *MessageBuilder {
+	b.options.EnableDuplicateCheck = true
+	b.options.DuplicateCheckInterval = int(interval / time.Second)
+	return b
+}
+
+// Priority 设置消息优先级
+func (b *MessageBuilder) Priority(priority Priority) *MessageBuilder {
+	b.options.Priority = priority
+	return b
+}
+
+// TTL 设置整个发送过程的时限
+func (b *MessageBuilder) TTL(ttl time.Duration) *MessageBuilder {
+	b.options.TTL = ttl
+	return b
+}
+
+// Options 设置消息配置，替换之前设置的配置
+func (b *MessageBuilder) Options(options MessageOptions) *MessageBuilder {
+	b.options = options
+	return b
+}
+
+// setErr 记录第一个错误
+func (b *MessageBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build 校验并返回接收人、消息及配置，可直接传给 Send
+func (b *MessageBuilder) Build() (MessageReceiver, interface{}, *MessageOptions, error) {
+	options := b.options
+	if b.err != nil {
+		return b.receiver, b.message, &options, b.err
+	}
+	if _, err := buildMessageBody(b.receiver, b.message, &options, 0); err != nil {
+		return b.receiver, b.message, &options, err
+	}
+	if strings.Contains(b.receiver.ToUser, "@all") && b.receiver.ToUser != "@all" {
+		return b.receiver, b.message, &options, errors.New("@all can not be combined with other users")
+	}
+	return b.receiver, b.message, &options, nil
+}
+
+// Send 校验通过后使用 n 发送消息
+func (b *MessageBuilder) Send(n *Notify) (MessageResult, error) {
+	receiver, message, options, err := b.Build()
+	if err != nil {
+		return MessageResult{}, err
+	}
+	return n.Send(receiver, message, options)
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMessageBuilder_Build(t *testing.T) {
+	receiver, message, options, err := Message().ToUsers("a", "b").ToTags("1").Text("hi").Safe().Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v, want no error", err)
+	}
+	if receiver.ToUser != "a|b" || receiver.ToTag != "1" {
+		t.Errorf("Build() receiver = %+v, want touser a|b and totag 1", receiver)
+	}
+	if message != (Text{Content: "hi"}) || !options.Safe {
+		t.Errorf("Build() message = %v, options = %+v", message, options)
+	}
+
+	tests := []struct {
+		name    string
+		builder *MessageBuilder
+	}{
+		{"no receiver", Message().Text("hi")},
+		{"no message", Message().ToUsers("a")},
+		{"two messages", Message().ToUsers("a").Text("hi").Markdown("hi")},
+		{"invalid textcard", Message().ToUsers("a").TextCard("", "d", "https://example.com")},
+		{"all with users", Message().ToAll().ToUsers("a").Text("hi")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := tt.builder.Build(); err == nil {
+				t.Errorf("Build() want error")
+			}
+		})
+	}
+}
+
+func TestMessageBuilder_Send(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	if _, err := Message().ToAll().Text("hi").Send(n); err != nil {
+		t.Errorf("Send() error = %v, want no error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidSignature 回调签名校验失败
+var ErrInvalidSignature = errors.New("callback signature mismatch")
+
+// Callback 接收消息回调（如任务卡片按钮点击事件）的签名校验及解密，对应官方 WXBizMsgCrypt 方案
+//
+// 文档见：https://developer.work.weixin.qq.com/document/path/90968
+type Callback struct {
+	token      string
+	aesKey     []byte
+	receiverID string
+}
+
+// NewCallback 使用应用接收消息设置中的 Token、EncodingAESKey 及企业ID创建回调处理
+func NewCallback(token, encodingAESKey, corpID string) (*Callback, error) {
+	key, err := base64.StdEncoding.DecodeString(encodingAESKey + "=")
+	if err != nil {
+		return nil, fmt.Errorf("decode encoding aes key error: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid encoding aes key length: %d", len(encodingAESKey))
+	}
+	return &Callback{token: token, aesKey: key, receiverID: corpID}, nil
+}
+
+// VerifyURL 校验回调 URL 验证请求的签名并解密 echostr，返回的明文需原样响应
+func (c *Callback) VerifyURL(signature, timestamp, nonce, echostr string) (string, error) {
+	if !c.verify(signature, timestamp, nonce, echostr) {
+		return "", ErrInvalidSignature
+	}
+	b, err := c.decrypt(echostr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// DecryptMessage 校验回调消息签名并解密，body 为回调请求的 XML 内容，返回解密后的消息 XML
+func (c *Callback) DecryptMessage(signature, timestamp, nonce string, body []byte) ([]byte, error) {
+	var msg struct {
+		ToUserName string `xml:"ToUserName"`
+		Encrypt    string `xml:"Encrypt"`
+		AgentID    string `xml:"AgentID"`
+	}
+	if err := xml.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("decode callback body error: %w", err)
+	}
+	if !c.verify(signature, timestamp, nonce, msg.Encrypt) {
+		return nil, ErrInvalidSignature
+	}
+	return c.decrypt(msg.Encrypt)
+}
+
+// verify 校验 sha1(sort(token, timestamp, nonce, encrypted)) 与 signature 是否一致
+func (c *Callback) verify(signature, timestamp, nonce, encrypted string) bool {
+	expected := callbackSignature(c.token, timestamp, nonce, encrypted)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1
+}
+
+func callbackSignature(token, timestamp, nonce, encrypted string) string {
+	s := []string{token, timestamp, nonce, encrypted}
+	sort.Strings(s)
+	sum := sha1.Sum([]byte(strings.Join(s, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// decrypt 解密内容，明文格式为 random(16B) + msg_len(4B) + msg + receiveid，使用 PKCS#7 填充（块大小32）
+func (c *Callback) decrypt(encrypted string) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("decode encrypted content error: %w", err)
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid encrypted content length")
+	}
+	block, err := aes.NewCipher(c.aesKey)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher error: %w", err)
+	}
+	plain := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, c.aesKey[:aes.BlockSize]).CryptBlocks(plain, ciphertext)
+
+	pad := int(plain[len(plain)-1])
+	if pad < 1 || pad > 32 || pad > len(plain) || !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, errors.New("invalid padding of decrypted content")
+	}
+	plain = plain[:len(plain)-pad]
+	if len(plain) < 20 {
+		return nil, errors.New("invalid decrypted content length")
+	}
+	msgLen := int(binary.BigEndian.Uint32(plain[16:20]))
+	if 20+msgLen > len(plain) {
+		return nil, errors.New("invalid decrypted message length")
+	}
+	if receiverID := string(plain[20+msgLen:]); receiverID != c.receiverID {
+		return nil, fmt.Errorf("receiver id mismatch: %s", receiverID)
+	}
+	return plain[20 : 20+msgLen], nil
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+// encryptCallback 按 WXBizMsgCrypt 方案加密，模拟企业微信回调内容
+func encryptCallback(t *testing.T, key []byte, msg, receiverID string) string {
+	plain := make([]byte, 20, 20+len(msg)+len(receiverID)+32)
+	copy(plain, bytes.Repeat([]byte{'r'}, 16))
+	binary.BigEndian.PutUint32(plain[16:], uint32(len(msg)))
+	plain = append(plain, msg...)
+	plain = append(plain, receiverID...)
+	pad := 32 - len(plain)%32
+	plain = append(plain, bytes.Repeat([]byte{byte(pad)}, pad)...)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, key[:aes.BlockSize]).CryptBlocks(ciphertext, plain)
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func TestCallback(t *testing.T) {
+	key := bytes.Repeat([]byte{0x5a}, 32)
+	encodingAESKey := base64.StdEncoding.EncodeToString(key)[:43]
+	c, err := NewCallback("token", encodingAESKey, "corp")
+	if err != nil {
+		t.Fatalf("NewCallback() error = %v, want no error", err)
+	}
+
+	t.Run("VerifyURL", func(t *testing.T) {
+		echostr := encryptCallback(t, key, "1616140317555161061", "corp")
+		signature := callbackSignature("token", "1409659589", "263014780", echostr)
+		got, err := c.VerifyURL(signature, "1409659589", "263014780", echostr)
+		if err != nil || got != "1616140317555161061" {
+			t.Errorf("VerifyURL() = %v, %v, want echostr plaintext", got, err)
+		}
+		if _, err := c.VerifyURL("bad", "1409659589", "263014780", echostr); err != ErrInvalidSignature {
+			t.Errorf("VerifyURL() error = %v, want %v", err, ErrInvalidSignature)
+		}
+	})
+
+	t.Run("DecryptMessage", func(t *testing.T) {
+		msg := "<xml><EventKey><![CDATA[k1]]></EventKey></xml>"
+		encrypted := encryptCallback(t, key, msg, "corp")
+		body := []byte(fmt.Sprintf("<xml><ToUserName><![CDATA[corp]]></ToUserName><Encrypt><![CDATA[%s]]></Encrypt><AgentID><![CDATA[1]]></AgentID></xml>", encrypted))
+		signature := callbackSignature("token", "1409659589", "263014780", encrypted)
+		got, err := c.DecryptMessage(signature, "1409659589", "263014780", body)
+		if err != nil || string(got) != msg {
+			t.Errorf("DecryptMessage() = %s, %v, want %s", got, err, msg)
+		}
+
+		other := encryptCallback(t, key, msg, "other")
+		body = []byte(fmt.Sprintf("<xml><Encrypt><![CDATA[%s]]></Encrypt></xml>", other))
+		signature = callbackSignature("token", "1409659589", "263014780", other)
+		if _, err := c.DecryptMessage(signature, "1409659589", "263014780", body); err == nil {
+			t.Errorf("DecryptMessage() with other receiver id want error")
+		}
+	})
+}
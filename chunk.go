@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// 单次发送的接收人个数上限
+const (
+	maxUsersPerSend   = 1000
+	maxPartiesPerSend = 100
+	maxTagsPerSend    = 100
+)
+
+// SendChunked 接收人超过单次发送上限（成员1000个，部门及标签各100个）时拆分为多次发送同一消息，
+// 返回合并后的结果及每次发送的结果。合并结果的无效接收人为各次结果的并集，错误码为第一个非 0 的错误码，
+// 某次发送失败时仍继续发送其余部分，返回第一个错误。与 Send 相同合并默认配置，设置了 DedupKey 时对整次发送去重
+func (n *Notify) SendChunked(receiver MessageReceiver, message interface{}, options *MessageOptions) (MessageResult, []MessageResult, error) {
+	ctx := context.Background()
+	if err := n.requireAgent(); err != nil {
+		return MessageResult{}, nil, err
+	}
+	options = n.resolveOptions(ctx, message, options)
+	if options == nil || options.DedupKey == "" || options.DedupWindow <= 0 {
+		return n.sendChunked(ctx, receiver, message, options)
+	}
+
+	key := options.DedupKey
+	if !n.reserveDedup(key, options.DedupWindow) {
+		return MessageResult{}, nil, ErrDuplicateSuppressed
+	}
+	combined, results, err := n.sendChunked(ctx, receiver, message, withoutDedup(options))
+	if (err != nil && !errors.Is(err, ErrQuietHoursDeferred) && !errors.Is(err, ErrSendQueued)) || combined.ErrorCode != 0 {
+		n.releaseDedup(key)
+	}
+	return combined, results, err
+}
+
+// sendChunked 使用已合并的配置拆分发送，AutoChunk 时由 sendResolved 调用
+func (n *Notify) sendChunked(ctx context.Context, receiver MessageReceiver, message interface{}, options *MessageOptions) (MessageResult, []MessageResult, error) {
+	var chunkOptions *MessageOptions
+	if options != nil {
+		o := *options
+		o.AutoChunk = false
+		chunkOptions = &o
+	}
+
+	var combined MessageResult
+	var results []MessageResult
+	var firstErr error
+	for _, chunk := range chunkReceiver(receiver) {
+		result, err := n.sendResolved(ctx, chunk, message, chunkOptions)
+		results = append(results, result)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if combined.ErrorCode == 0 && result.ErrorCode != 0 {
+			combined.ErrorCode = result.ErrorCode
+			combined.ErrorMsg = result.ErrorMsg
+		} else if combined.ErrorMsg == "" {
+			combined.ErrorMsg = result.ErrorMsg
+		}
+		combined.InvalidUser = appendIDs(combined.InvalidUser, []string{result.InvalidUser})
+		combined.InvalidParty = appendIDs(combined.InvalidParty, []string{result.InvalidParty})
+		combined.InvalidTag = appendIDs(combined.InvalidTag, []string{result.InvalidTag})
+	}
+	return combined, results, firstErr
+}
+
+// chunkReceiver 按单次发送上限拆分接收人，未超过上限或发送给 @all 时返回原接收人
+func chunkReceiver(receiver MessageReceiver) []MessageReceiver {
+	if receiver.ToUser == "@all" {
+		return []MessageReceiver{receiver}
+	}
+	users := splitIDs(receiver.ToUser, maxUsersPerSend)
+	parties := splitIDs(receiver.ToParty, maxPartiesPerSend)
+	tags := splitIDs(receiver.ToTag, maxTagsPerSend)
+
+	count := len(users)
+	if len(parties) > count {
+		count = len(parties)
+	}
+	if len(tags) > count {
+		count = len(tags)
+	}
+	if count <= 1 {
+		return []MessageReceiver{receiver}
+	}
+
+	chunks := make([]MessageReceiver, count)
+	for i := range chunks {
+		if i < len(users) {
+			chunks[i].ToUser = users[i]
+		}
+		if i < len(parties) {
+			chunks[i].ToParty = parties[i]
+		}
+		if i < len(tags) {
+			chunks[i].ToTag = tags[i]
+		}
+	}
+	return chunks
+}
+
+// splitIDs 将‘|’分隔的 id 列表按每组最多 size 个拆分
+func splitIDs(ids string, size int) []string {
+	if ids == "" {
+		return nil
+	}
+	list := strings.Split(ids, "|")
+	groups := make([]string, 0, (len(list)+size-1)/size)
+	for start := 0; start < len(list); start += size {
+		end := start + size
+		if end > len(list) {
+			end = len(list)
+		}
+		groups = append(groups, strings.Join(list[start:end], "|"))
+	}
+	return groups
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestChunkReceiver(t *testing.T) {
+	users := make([]string, 2500)
+	for i := range users {
+		users[i] = "u" + strconv.Itoa(i)
+	}
+	chunks := chunkReceiver(MessageReceiver{ToUser: strings.Join(users, "|"), ToTag: "1|2"})
+	if len(chunks) != 3 {
+		t.Fatalf("chunkReceiver() = %d chunks, want 3", len(chunks))
+	}
+	if countIDs(chunks[0].ToUser) != 1000 || countIDs(chunks[2].ToUser) != 500 || chunks[0].ToTag != "1|2" || chunks[1].ToTag != "" {
+		t.Errorf("chunkReceiver() chunk sizes = %d, %d, tags = %q, %q", countIDs(chunks[0].ToUser), countIDs(chunks[2].ToUser), chunks[0].ToTag, chunks[1].ToTag)
+	}
+
+	if got := chunkReceiver(MessageReceiver{ToUser: "a|b"}); len(got) != 1 {
+		t.Errorf("chunkReceiver() = %d chunks, want 1", len(got))
+	}
+}
+
+func TestNotify_SendChunked(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ToParty string `json:"toparty"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		invalid := strings.Split(body.ToParty, "|")[0]
+		atomic.AddInt32(&sends, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","invalidparty":"` + invalid + `"}`))
+	})
+	parties := make([]string, 150)
+	for i := range parties {
+		parties[i] = strconv.Itoa(i + 1)
+	}
+	receiver := MessageReceiver{ToParty: strings.Join(parties, "|")}
+
+	combined, results, err := n.SendChunked(receiver, Text{Content: "hi"}, nil)
+	if err != nil {
+		t.Fatalf("SendChunked() error = %v, want no error", err)
+	}
+	if len(results) != 2 || combined.InvalidParty != "1|101" || combined.ErrorMsg != "ok" {
+		t.Errorf("SendChunked() = %+v, %d results, want invalid party 1|101 and 2 results", combined, len(results))
+	}
+
+	result, err := n.Send(receiver, Text{Content: "hi"}, &MessageOptions{AutoChunk: true})
+	if err != nil || result.InvalidParty != "1|101" {
+		t.Errorf("Send() with AutoChunk = %+v, %v, want invalid party 1|101", result, err)
+	}
+	if got := atomic.LoadInt32(&sends); got != 4 {
+		t.Errorf("sends = %d, want 4", got)
+	}
+}
+
+func TestNotify_SendChunkedOptions(t *testing.T) {
+	var body map[string]interface{}
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.DefaultOptions = &MessageOptions{Safe: true}
+
+	if _, _, err := n.SendChunked(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("SendChunked() error = %v", err)
+	}
+	if body["safe"] != float64(1) {
+		t.Errorf("request body = %v, want safe from DefaultOptions", body)
+	}
+
+	body = nil
+	n.agentID = 0
+	if _, _, err := n.SendChunked(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); !errors.Is(err, ErrNoAgentID) {
+		t.Errorf("SendChunked() error = %v, want %v", err, ErrNoAgentID)
+	}
+	if body != nil {
+		t.Errorf("request body = %v, want nothing sent without agentid", body)
+	}
+}
@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultTimeout 默认客户端的请求总超时
+const defaultTimeout = 10 * time.Second
+
+// TimeoutConfig HTTP 请求各阶段的超时，零值字段使用默认值。
+// 连接阶段超时返回 Op 为 "dial" 的 *net.OpError，可据此区分企业微信无法访问与响应缓慢
+type TimeoutConfig struct {
+	Dial           time.Duration // 建立 TCP 连接的超时，默认 30 秒
+	TLSHandshake   time.Duration // TLS 握手超时，默认 10 秒
+	ResponseHeader time.Duration // 发送请求后等待响应头的超时，默认不限制
+	Total          time.Duration // 包括读取响应内容的请求总超时，默认 10 秒，上传可通过 UploadConfig.Timeout 单独设置
+}
+
+// NewHTTPClient 根据超时配置创建 HTTP 客户端，可通过 SetHTTPClient 使用
+func NewHTTPClient(cfg TimeoutConfig) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Dial > 0 {
+		dialer := &net.Dialer{Timeout: cfg.Dial, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if cfg.TLSHandshake > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshake
+	}
+	if cfg.ResponseHeader > 0 {
+		transport.ResponseHeaderTimeout = cfg.ResponseHeader
+	}
+	total := cfg.Total
+	if total <= 0 {
+		total = defaultTimeout
+	}
+	return &http.Client{Transport: transport, Timeout: total}
+}
+
+// SetTimeouts 使用 NewHTTPClient 创建的客户端发送请求，会替换 SetHTTPClient 设置的客户端
+func (n *Notify) SetTimeouts(cfg TimeoutConfig) {
+	n.SetHTTPClient(NewHTTPClient(cfg))
+}
@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	c := NewHTTPClient(TimeoutConfig{Dial: time.Second, TLSHandshake: 2 * time.Second, ResponseHeader: 3 * time.Second})
+	transport := c.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 3*time.Second || c.Timeout != defaultTimeout {
+		t.Errorf("NewHTTPClient() tls = %v, header = %v, total = %v", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout, c.Timeout)
+	}
+}
+
+func TestNotify_SetTimeouts(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.SetTimeouts(TimeoutConfig{ResponseHeader: 50 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err == nil {
+		t.Errorf("Send() want response header timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Send() took %v, want abort after response header timeout", elapsed)
+	}
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	// maxResponseBytes 读取接口返回内容的上限
+	maxResponseBytes = 1 << 20
+	// maxSnippetBytes 解析失败时错误信息中附带的原始内容长度上限
+	maxSnippetBytes = 256
+)
+
+// DecodeError 接口返回内容无法解析，如网关返回的 HTML 错误页
+type DecodeError struct {
+	Body       []byte // 原始返回内容，最多 maxSnippetBytes 字节
+	Err        error  // 解析错误
+	StatusCode int    // 发送消息时返回的 HTTP 状态码，其他接口为 0
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%v, body: %q", e.Err, e.Body)
+}
+
+// Unwrap 返回原始的解析错误
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Codec 请求及返回内容的 JSON 编解码，默认使用 encoding/json，可替换为 jsoniter、sonic 等实现。
+// 替换的实现应按键排序编码 map，以保证发送内容与 Marshal 的输出一致
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// stdCodec 基于 encoding/json 的默认实现
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// SetCodec 设置消息发送及接口返回使用的 JSON 编解码，nil 时恢复默认实现
+func (n *Notify) SetCodec(codec Codec) {
+	if codec == nil {
+		codec = stdCodec{}
+	}
+	n.codec = codec
+}
+
+// decode 读取 r 的内容并解析到 v，内容超过 maxResponseBytes 时截断
+func (n *Notify) decode(r io.Reader, v interface{}) error {
+	b, err := readResponse(r)
+	if err != nil {
+		return err
+	}
+	return n.unmarshal(b, v)
+}
+
+// readResponse 读取接口返回内容，最多 maxResponseBytes 字节
+func readResponse(r io.Reader) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(r, maxResponseBytes))
+}
+
+// unmarshal 解析接口返回内容，失败时返回附带原始内容片段的 DecodeError
+func (n *Notify) unmarshal(b []byte, v interface{}) error {
+	if err := n.codec.Unmarshal(b, v); err != nil {
+		return &DecodeError{Body: snippet(b), Err: err}
+	}
+	return nil
+}
+
+// snippet 截取 b 的前 maxSnippetBytes 字节，不截断 UTF-8 字符
+func snippet(b []byte) []byte {
+	if len(b) <= maxSnippetBytes {
+		return b
+	}
+	end := maxSnippetBytes
+	for end > 0 && !utf8.RuneStart(b[end]) {
+		end--
+	}
+	return b[:end]
+}
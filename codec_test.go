@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingCodec 统计编解码次数的 Codec
+type countingCodec struct {
+	marshal, unmarshal int32
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&c.marshal, 1)
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt32(&c.unmarshal, 1)
+	return json.Unmarshal(data, v)
+}
+
+func TestNotify_SetCodec(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	codec := &countingCodec{}
+	n.SetCodec(codec)
+
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	// 编码消息一次，解码 token 及发送结果各一次
+	if codec.marshal != 1 || codec.unmarshal != 2 {
+		t.Errorf("codec calls marshal = %d, unmarshal = %d, want 1 and 2", codec.marshal, codec.unmarshal)
+	}
+}
+
+func TestNotify_decodeError(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html>502 Bad Gateway</html>"))
+	})
+
+	_, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Send() error = %v, want DecodeError", err)
+	}
+	if string(decodeErr.Body) != "<html>502 Bad Gateway</html>" {
+		t.Errorf("DecodeError.Body = %q, want raw body", decodeErr.Body)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	b := []byte(strings.Repeat("a", maxSnippetBytes-1) + "中文")
+	if got := snippet(b); string(got) != strings.Repeat("a", maxSnippetBytes-1) {
+		t.Errorf("snippet() = %q, want cut before multi-byte rune", got)
+	}
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config 客户端配置，用于 NewWithConfig 一次性创建并校验客户端，零值字段使用默认值
+type Config struct {
+	CorpID         string                 // 企业ID，必填
+	AgentID        int64                  // 应用id，仅调用企业级接口时可为 0
+	AppSecret      string                 // 应用secret，与 SecretProvider 至少设置一个
+	SecretProvider func() (string, error) // 刷新 token 前获取应用secret，见 SetSecretProvider
+
+	HTTPClient *http.Client  // 发送请求使用的客户端，默认 10 秒超时
+	Timeouts   TimeoutConfig // 未设置 HTTPClient 时按分阶段超时创建客户端，见 NewHTTPClient
+	BaseURL    string        // 接口地址前缀，默认 https://qyapi.weixin.qq.com/cgi-bin，可设置为代理地址
+	Codec      Codec         // JSON 编解码，默认 encoding/json
+
+	TokenPersist      bool          // 是否将 token 缓存到文件
+	CacheFilePath     string        // 缓存文件路径，默认 .notify
+	CacheLockTimeout  time.Duration // 读写缓存文件时等待文件锁的时长，默认 1 秒
+	Store             Store         // 共享存储，设置后 token 不再缓存到文件
+	StalenessGrace    time.Duration // Store 读取失败时过期 token 的宽限时长
+	TokenRetries      int           // token 失效时的重试次数，最大 3，0 表示使用默认的 1 次，不重试时设置 DisableTokenRetry
+	DisableTokenRetry bool          // token 失效时不重新获取 token 重试，不能与 TokenRetries 同时设置
+	TokenExpiryMargin time.Duration // 计算 token 过期时间时预留的时长，默认 60 秒
+	MaxBodySize       int64         // 消息请求体的最大字节数，默认 8MB
+
+	UploadConfig   UploadConfig
+	DefaultOptions *MessageOptions
+	Environment    string
+	SendPolicy     SendPolicy
+
+	CircuitBreakerThreshold int           // 熔断的连续失败次数，0 表示不熔断
+	CircuitBreakerCooldown  time.Duration // 熔断时长
+	RateLimit               int           // 每分钟最多发送的消息数，0 表示不限流，见 SetRateLimit
+	RateLimitBurst          int           // 限流允许的突发消息数，默认 1
+
+	Interceptors []SendInterceptor   // 按顺序注册的拦截器
+	OnSend       func(info SendInfo) // 发送完成回调
+	Tracer       Tracer
+}
+
+// NewWithConfig 根据配置创建客户端，配置无效时返回错误
+func NewWithConfig(cfg Config) (*Notify, error) {
+	if cfg.CorpID == "" {
+		return nil, errors.New("config corp id can not be empty")
+	}
+	if cfg.AppSecret == "" && cfg.SecretProvider == nil {
+		return nil, errors.New("config app secret or secret provider must be set")
+	}
+	if cfg.TokenRetries < 0 || cfg.TokenRetries > maxTokenRetries {
+		return nil, fmt.Errorf("config token retries must be between 0 and %d", maxTokenRetries)
+	}
+	if cfg.DisableTokenRetry && cfg.TokenRetries > 0 {
+		return nil, errors.New("config token retries can not be set when token retry is disabled")
+	}
+	if cfg.RateLimit < 0 {
+		return nil, errors.New("config rate limit can not be negative")
+	}
+	if cfg.CircuitBreakerThreshold > 0 && cfg.CircuitBreakerCooldown <= 0 {
+		return nil, errors.New("config circuit breaker cooldown must be positive")
+	}
+	if cfg.BaseURL != "" {
+		u, err := url.Parse(cfg.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("config base url %q is invalid", cfg.BaseURL)
+		}
+	}
+
+	n := New(cfg.CorpID, cfg.AgentID, cfg.AppSecret)
+	n.SetSecretProvider(cfg.SecretProvider)
+	n.SetHTTPClient(cfg.HTTPClient)
+	if cfg.HTTPClient == nil && cfg.Timeouts != (TimeoutConfig{}) {
+		n.SetTimeouts(cfg.Timeouts)
+	}
+	n.SetCodec(cfg.Codec)
+	if cfg.BaseURL != "" {
+		n.baseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	}
+	if cfg.CacheFilePath != "" {
+		n.SetCacheFilePath(cfg.CacheFilePath)
+	}
+	if cfg.TokenPersist {
+		if err := n.EnableTokenPersistE(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Store != nil {
+		n.SetStore(cfg.Store)
+	}
+	n.StalenessGrace = cfg.StalenessGrace
+	n.TokenExpiryMargin = cfg.TokenExpiryMargin
+	n.CacheLockTimeout = cfg.CacheLockTimeout
+	n.MaxBodySize = cfg.MaxBodySize
+	if cfg.DisableTokenRetry {
+		n.SetTokenRetries(0)
+	} else if cfg.TokenRetries > 0 {
+		n.SetTokenRetries(cfg.TokenRetries)
+	}
+	n.UploadConfig = cfg.UploadConfig
+	n.DefaultOptions = cfg.DefaultOptions
+	n.Environment = cfg.Environment
+	n.SetSendPolicy(cfg.SendPolicy)
+	if cfg.CircuitBreakerThreshold > 0 {
+		n.EnableCircuitBreaker(cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown)
+	}
+	if cfg.RateLimit > 0 {
+		n.SetRateLimit(cfg.RateLimit, cfg.RateLimitBurst)
+	}
+	for _, interceptor := range cfg.Interceptors {
+		n.Use(interceptor)
+	}
+	n.OnSend(cfg.OnSend)
+	n.SetTracer(cfg.Tracer)
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewWithConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no corp id", Config{AppSecret: "s"}},
+		{"no secret", Config{CorpID: "c"}},
+		{"bad token retries", Config{CorpID: "c", AppSecret: "s", TokenRetries: 4}},
+		{"conflicting token retries", Config{CorpID: "c", AppSecret: "s", TokenRetries: 2, DisableTokenRetry: true}},
+		{"bad rate limit", Config{CorpID: "c", AppSecret: "s", RateLimit: -1}},
+		{"no cooldown", Config{CorpID: "c", AppSecret: "s", CircuitBreakerThreshold: 3}},
+		{"bad base url", Config{CorpID: "c", AppSecret: "s", BaseURL: "qyapi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWithConfig(tt.cfg); err == nil {
+				t.Errorf("NewWithConfig() want error")
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gettoken" {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	var infos []SendInfo
+	n, err := NewWithConfig(Config{
+		CorpID:                  t.Name(),
+		AgentID:                 1,
+		AppSecret:               "secret",
+		BaseURL:                 server.URL + "/",
+		TokenPersist:            true,
+		CacheFilePath:           filepath.Join(t.TempDir(), "cache"),
+		CircuitBreakerThreshold: 3,
+		CircuitBreakerCooldown:  time.Minute,
+		RateLimit:               600,
+		Environment:             "staging",
+		OnSend:                  func(info SendInfo) { infos = append(infos, info) },
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v, want no error", err)
+	}
+	if _, err = n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if len(infos) != 1 || !n.TokenPersist || n.breaker.threshold != 3 || n.limiter.rate != 10 {
+		t.Errorf("NewWithConfig() hooks = %d, persist = %v, breaker threshold = %d, rate = %v", len(infos), n.TokenPersist, n.breaker.threshold, n.limiter.rate)
+	}
+}
+
+func TestNewWithConfigTokenRetries(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want int
+	}{
+		{"default", Config{}, 1},
+		{"twice", Config{TokenRetries: 2}, 2},
+		{"disabled", Config{DisableTokenRetry: true}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.CorpID, tt.cfg.AppSecret = "c", "s"
+			n, err := NewWithConfig(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewWithConfig() error = %v", err)
+			}
+			if n.tokenRetries != tt.want {
+				t.Errorf("NewWithConfig() token retries = %d, want %d", n.tokenRetries, tt.want)
+			}
+		})
+	}
+}
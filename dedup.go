@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDuplicateSuppressed 去重时间窗口内已发送过相同去重键的消息，本次未发送，见 MessageOptions.DedupKey
+var ErrDuplicateSuppressed = errors.New("message suppressed by dedup key")
+
+// dedupCache 未设置 Store 时在内存中记录去重键的过期时间
+type dedupCache struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+// sendDedup 按 DedupKey 去重后发送。发送前即占用去重键，避免并发发送相同告警，
+// 发送失败、返回错误码或免打扰时段被丢弃时释放去重键，以便重新发送；延迟发送及加入故障队列的消息视为已发送。
+// 设置了 Store 时去重记录保存在 Store 中，由多个进程共享，Store 不支持原子写入，并发时可能重复发送
+func (n *Notify) sendDedup(ctx context.Context, receiver MessageReceiver, message interface{}, options *MessageOptions) (MessageResult, error) {
+	key, window := options.DedupKey, options.DedupWindow
+	if !n.reserveDedup(key, window) {
+		return MessageResult{}, ErrDuplicateSuppressed
+	}
+
+	result, err := n.sendResolved(ctx, receiver, message, withoutDedup(options))
+	if errors.Is(err, ErrQuietHoursDeferred) || errors.Is(err, ErrSendQueued) {
+		return result, err
+	}
+	if err != nil || result.ErrorCode != 0 {
+		n.releaseDedup(key)
+	}
+	return result, err
+}
+
+// withoutDedup 返回清除了去重键的配置副本，用于去重后及故障队列重发时的发送
+func withoutDedup(options *MessageOptions) *MessageOptions {
+	if options == nil {
+		return nil
+	}
+	o := *options
+	o.DedupKey = ""
+	o.DedupWindow = 0
+	return &o
+}
+
+// reserveDedup 占用去重键 window 时长，去重键已被占用时返回 false。Store 读取失败时不去重
+func (n *Notify) reserveDedup(key string, window time.Duration) bool {
+	if n.store != nil {
+		storeKey := n.storeKey("dedup:" + key)
+		if _, err := n.store.Get(storeKey); err == nil {
+			return false
+		}
+		_ = n.store.Set(storeKey, []byte{1}, window)
+		return true
+	}
+
+	now := n.now()
+	n.dedup.mu.Lock()
+	defer n.dedup.mu.Unlock()
+	if n.dedup.expires == nil {
+		n.dedup.expires = make(map[string]time.Time)
+	}
+	for k, expiresAt := range n.dedup.expires {
+		if !now.Before(expiresAt) {
+			delete(n.dedup.expires, k)
+		}
+	}
+	if _, ok := n.dedup.expires[key]; ok {
+		return false
+	}
+	n.dedup.expires[key] = now.Add(window)
+	return true
+}
+
+// releaseDedup 释放去重键
+func (n *Notify) releaseDedup(key string) {
+	if n.store != nil {
+		_ = n.store.Delete(n.storeKey("dedup:" + key))
+		return
+	}
+	n.dedup.mu.Lock()
+	delete(n.dedup.expires, key)
+	n.dedup.mu.Unlock()
+}
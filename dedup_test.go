@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_SendDedup(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&sends, 1) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	now := time.Date(2022, 7, 9, 10, 0, 0, 0, time.Local)
+	n.now = func() time.Time { return now }
+	receiver := MessageReceiver{ToUser: "@all"}
+	options := &MessageOptions{DedupKey: "disk-full:db1", DedupWindow: time.Minute}
+
+	// 发送失败时释放去重键
+	if result, _ := n.Send(receiver, Text{Content: "disk full at 10:00"}, options); result.ErrorCode != errCodeSystemBusy {
+		t.Fatalf("Send() = %+v, want system busy", result)
+	}
+	if _, err := n.Send(receiver, Text{Content: "disk full at 10:00"}, options); err != nil {
+		t.Fatalf("Send() error = %v, want resend after failure", err)
+	}
+	if _, err := n.Send(receiver, Text{Content: "disk full at 10:01"}, options); !errors.Is(err, ErrDuplicateSuppressed) {
+		t.Errorf("Send() error = %v, want %v", err, ErrDuplicateSuppressed)
+	}
+	if got := atomic.LoadInt32(&sends); got != 2 {
+		t.Errorf("sends = %d, want 2", got)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := n.Send(receiver, Text{Content: "disk full at 10:01"}, options); err != nil {
+		t.Errorf("Send() error = %v, want send after window", err)
+	}
+}
+
+func TestNotify_SendDedupStore(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}
+	store := NewMemoryStore()
+	a, b := newTestNotify(t, handler), newTestNotify(t, handler)
+	a.SetStore(store)
+	b.SetStore(store)
+
+	options := &MessageOptions{DedupKey: "incident-1", DedupWindow: time.Minute}
+	if _, err := a.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, options); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if _, err := b.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi again"}, options); !errors.Is(err, ErrDuplicateSuppressed) {
+		t.Errorf("Send() error = %v, want %v across clients sharing a store", err, ErrDuplicateSuppressed)
+	}
+}
+
+func TestNotify_SendDedupDefaultOptions(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.DefaultOptions = &MessageOptions{DedupKey: "k", DedupWindow: time.Minute}
+
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want first send with default dedup key sent", err)
+	}
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); !errors.Is(err, ErrDuplicateSuppressed) {
+		t.Errorf("Send() error = %v, want %v", err, ErrDuplicateSuppressed)
+	}
+	if got := atomic.LoadInt32(&sends); got != 1 {
+		t.Errorf("sends = %d, want 1", got)
+	}
+
+	if err := n.Reset(false); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Errorf("Send() error = %v, want dedup record cleared by Reset", err)
+	}
+}
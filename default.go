@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+var (
+	defaultMu       sync.Mutex
+	defaultClient   *Notify
+	defaultReceiver MessageReceiver
+)
+
+// Default 返回由环境变量创建的默认客户端，与命令行的环境变量一致：
+// NOTIFY_CORPID、NOTIFY_AGENTID、NOTIFY_APPSECRET 为应用配置，
+// NOTIFY_USER、NOTIFY_PARTY、NOTIFY_TAG 为默认接收者，均未设置时发送给 @all。
+// 创建成功后复用同一客户端，之后修改环境变量不再生效；环境变量缺失时返回错误且不缓存，设置后再次调用即可创建。可并发调用
+func Default() (*Notify, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultClient != nil {
+		return defaultClient, nil
+	}
+
+	corpID := os.Getenv("NOTIFY_CORPID")
+	appSecret := os.Getenv("NOTIFY_APPSECRET")
+	agentID, err := strconv.ParseInt(os.Getenv("NOTIFY_AGENTID"), 10, 64)
+	if corpID == "" || appSecret == "" || err != nil {
+		return nil, fmt.Errorf("default client requires NOTIFY_CORPID, NOTIFY_AGENTID and NOTIFY_APPSECRET")
+	}
+	defaultClient = New(corpID, agentID, appSecret)
+	defaultReceiver = MessageReceiver{
+		ToUser:  os.Getenv("NOTIFY_USER"),
+		ToParty: os.Getenv("NOTIFY_PARTY"),
+		ToTag:   os.Getenv("NOTIFY_TAG"),
+	}
+	if defaultReceiver == (MessageReceiver{}) {
+		defaultReceiver.ToUser = "@all"
+	}
+	return defaultClient, nil
+}
+
+// SendText 使用默认客户端向默认接收者发送文本消息
+func SendText(content string) (MessageResult, error) {
+	n, err := Default()
+	if err != nil {
+		return MessageResult{}, err
+	}
+	defaultMu.Lock()
+	receiver := defaultReceiver
+	defaultMu.Unlock()
+	return n.Send(receiver, Text{Content: content}, nil)
+}
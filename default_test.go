@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// resetDefault 清空默认客户端，测试结束时恢复
+func resetDefault(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		defaultMu.Lock()
+		defaultClient = nil
+		defaultReceiver = MessageReceiver{}
+		defaultMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestDefault(t *testing.T) {
+	resetDefault(t)
+	t.Setenv("NOTIFY_CORPID", "")
+	t.Setenv("NOTIFY_AGENTID", "")
+	t.Setenv("NOTIFY_APPSECRET", "")
+
+	if _, err := Default(); err == nil {
+		t.Fatalf("Default() want error without env")
+	}
+
+	// 环境变量设置后可再次创建，错误不会被缓存
+	t.Setenv("NOTIFY_CORPID", "corp")
+	t.Setenv("NOTIFY_AGENTID", "1000002")
+	t.Setenv("NOTIFY_APPSECRET", "secret")
+	t.Setenv("NOTIFY_USER", "")
+	t.Setenv("NOTIFY_PARTY", "2")
+	t.Setenv("NOTIFY_TAG", "")
+	n, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v, want client after env is set", err)
+	}
+	if n.corpID != "corp" || n.agentID != 1000002 {
+		t.Errorf("Default() = corp %q agent %d, want corp 1000002", n.corpID, n.agentID)
+	}
+	if defaultReceiver != (MessageReceiver{ToParty: "2"}) {
+		t.Errorf("default receiver = %+v, want party 2", defaultReceiver)
+	}
+
+	t.Setenv("NOTIFY_CORPID", "other")
+	if again, _ := Default(); again != n {
+		t.Errorf("Default() created a new client, want the first client reused")
+	}
+}
+
+func TestSendText(t *testing.T) {
+	resetDefault(t)
+	t.Setenv("NOTIFY_CORPID", "corp")
+	t.Setenv("NOTIFY_AGENTID", "1")
+	t.Setenv("NOTIFY_APPSECRET", "secret")
+	t.Setenv("NOTIFY_USER", "")
+	t.Setenv("NOTIFY_PARTY", "")
+	t.Setenv("NOTIFY_TAG", "")
+
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gettoken" {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	n, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	n.baseURL = server.URL
+
+	if _, err = SendText("hi"); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+	if body["touser"] != "@all" || body["msgtype"] != "text" {
+		t.Errorf("request body = %v, want text to @all", body)
+	}
+}
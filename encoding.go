@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// utf8BOM UTF-8 字节序标记
+const utf8BOM = "\ufeff"
+
+// ErrInvalidUTF8 消息内容不是合法的 UTF-8 编码，如 GBK、Latin-1 编码的内容
+var ErrInvalidUTF8 = errors.New("message content is not valid utf-8")
+
+// sanitizeUTF8 去除开头的 UTF-8 BOM，内容不是合法的 UTF-8 编码时返回 ErrInvalidUTF8
+func sanitizeUTF8(s string) (string, error) {
+	s = strings.TrimPrefix(s, utf8BOM)
+	if !utf8.ValidString(s) {
+		return s, ErrInvalidUTF8
+	}
+	return s, nil
+}
+
+// sanitizeMessage 处理文本及 markdown 消息的内容编码，其他类型原样返回
+func sanitizeMessage(message interface{}) (interface{}, error) {
+	switch m := message.(type) {
+	case Text:
+		content, err := sanitizeUTF8(m.Content)
+		return Text{Content: content}, err
+	case Markdown:
+		content, err := sanitizeUTF8(m.Content)
+		return Markdown{Content: content}, err
+	}
+	return message, nil
+}
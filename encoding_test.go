@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr error
+	}{
+		{name: "Plain", s: "告警", want: "告警"},
+		{name: "BOM", s: "\ufeff**告警**", want: "**告警**"},
+		{name: "Latin1", s: "caf\xe9", want: "caf\xe9", wantErr: ErrInvalidUTF8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sanitizeUTF8(tt.s)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("sanitizeUTF8() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarshal_SanitizeUTF8(t *testing.T) {
+	receiver := MessageReceiver{ToUser: "@all"}
+	b, err := Marshal(receiver, Markdown{Content: "\ufeff# 告警"}, nil, 1)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v, want no error", err)
+	}
+	if strings.Contains(string(b), "\ufeff") {
+		t.Errorf("Marshal() = %s, want BOM stripped", b)
+	}
+	if _, err = Marshal(receiver, Text{Content: "\xc4\xe3\xba\xc3"}, nil, 1); !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("Marshal() error = %v, want %v", err, ErrInvalidUTF8)
+	}
+}
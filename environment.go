@@ -0,0 +1,26 @@
+package notify
+
+import "strings"
+
+// labelMessage 在文本、markdown、文本卡片及任务卡片消息的内容或标题前添加环境标签，其他类型的消息原样返回
+func labelMessage(env string, message interface{}) interface{} {
+	if env == "" {
+		return message
+	}
+	label := "[" + strings.ToUpper(env) + "] "
+	switch m := message.(type) {
+	case Text:
+		m.Content = label + m.Content
+		return m
+	case Markdown:
+		m.Content = label + m.Content
+		return m
+	case TextCard:
+		m.Title = label + m.Title
+		return m
+	case TaskCard:
+		m.Title = label + m.Title
+		return m
+	}
+	return message
+}
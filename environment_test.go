@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNotify_Environment(t *testing.T) {
+	var sent string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		sent = string(b)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.Environment = "staging"
+
+	tests := []struct {
+		message interface{}
+		want    string
+	}{
+		{Text{Content: "hi"}, `"content":"[STAGING] hi"`},
+		{Markdown{Content: "**hi**"}, `"content":"[STAGING] **hi**"`},
+		{Text{Content: utf8BOM + "hi"}, `"content":"[STAGING] hi"`},
+		{TextCard{Title: "t", Description: "d", URL: "u"}, `"title":"[STAGING] t"`},
+		{Image{MediaID: "m1"}, `"image":{"media_id":"m1"}`},
+	}
+	for _, tt := range tests {
+		if _, err := n.Send(MessageReceiver{ToUser: "@all"}, tt.message, nil); err != nil {
+			t.Fatalf("Send() error = %v, want no error", err)
+		}
+		if !strings.Contains(sent, tt.want) {
+			t.Errorf("Send() body = %s, want containing %s", sent, tt.want)
+		}
+	}
+}
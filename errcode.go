@@ -0,0 +1,43 @@
+package notify
+
+import "fmt"
+
+// errorCodes 常见全局错误码说明，来源于官方全局错误码文档：https://developer.work.weixin.qq.com/document/path/90313
+var errorCodes = map[int64][2]string{
+	-1:    {"system busy, retry later", "系统繁忙"},
+	0:     {"ok", "请求成功"},
+	40001: {"invalid secret, check your appSecret", "不合法的secret参数"},
+	40003: {"invalid userid", "无效的UserID"},
+	40004: {"invalid media type", "不合法的媒体文件类型"},
+	40005: {"invalid type", "不合法的type参数"},
+	40006: {"invalid file size", "不合法的文件大小"},
+	40007: {"invalid media_id", "不合法的media_id参数"},
+	40008: {"invalid msgtype", "不合法的msgtype参数"},
+	40013: {"invalid corpid", "不合法的CorpID"},
+	40014: {"invalid access_token", "不合法的access_token"},
+	40056: {"invalid agentid", "不合法的agentid"},
+	40058: {"invalid parameter", "不合法的参数"},
+	40068: {"invalid tagid", "不合法的标签ID"},
+	41001: {"missing access_token", "缺少access_token参数"},
+	41004: {"missing secret", "缺少secret参数"},
+	42001: {"access_token expired", "access_token已过期"},
+	44001: {"empty media file", "多媒体文件为空"},
+	44004: {"empty text content", "文本消息content参数为空"},
+	45002: {"message content exceeds limit", "消息内容超过限制"},
+	45009: {"api call frequency exceeds limit", "接口调用超过限制"},
+	45033: {"api concurrent calls exceed limit", "接口并发调用超过限制"},
+	48002: {"api forbidden, not authorized for this agent", "API接口无权限调用"},
+	60011: {"no privilege to access the user, party or tag", "指定的成员/部门/标签参数无权限"},
+	60020: {"ip not in the app's trusted ip list", "不安全的访问IP"},
+	81013: {"user, party and tag are all invalid", "UserID、部门ID、标签ID全部非法或无权限"},
+	82001: {"user, party and tag are all empty", "指定的成员/部门/标签全部为空"},
+}
+
+// ErrorCodeMessage 返回错误码的中英文说明，如 "access_token expired（access_token已过期）"，未收录的错误码返回空字符串
+func ErrorCodeMessage(code int64) string {
+	m, ok := errorCodes[code]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s（%s）", m[0], m[1])
+}
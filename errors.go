@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// 消息校验错误，可通过 errors.Is 判断
+var (
+	// ErrNilMessage 消息为 nil
+	ErrNilMessage = errors.New("message can not be nil")
+	// ErrNoReceiver 未设置任何接收者
+	ErrNoReceiver = errors.New("message receiver not set, set at least one")
+	// ErrUnsupportedMessageType 消息不是已支持的消息类型，错误信息中包含实际类型
+	ErrUnsupportedMessageType = errors.New("unrecognized message type")
+	// ErrBodyTooLarge 编码后的请求体超过 MaxBodySize，错误信息中包含实际大小
+	ErrBodyTooLarge = errors.New("message body too large")
+)
+
+// APIError 接口返回的非 0 错误码
+type APIError struct {
+	Code int64  // 错误码
+	Msg  string // 错误信息
+}
+
+func (e *APIError) Error() string {
+	if m, ok := errorCodes[e.Code]; ok {
+		return fmt.Sprintf("[%d] %s: %s", e.Code, m[0], e.Msg)
+	}
+	return fmt.Sprintf("[%d] %s", e.Code, e.Msg)
+}
+
+// IPNotAllowedError 服务器出口 IP 不在应用的企业可信IP列表中，错误码 60020
+type IPNotAllowedError struct {
+	APIError
+	IP string // 被拒绝的出口 IP，错误信息中无法解析时为空
+}
+
+func (e *IPNotAllowedError) Error() string {
+	if e.IP == "" {
+		return fmt.Sprintf("egress ip is not in the app's trusted ip list: %s", e.APIError.Error())
+	}
+	return fmt.Sprintf("add %s to the app's trusted ip list: %s", e.IP, e.APIError.Error())
+}
+
+// Unwrap 返回原始的 APIError
+func (e *IPNotAllowedError) Unwrap() error {
+	return &e.APIError
+}
+
+// CredentialError corpID 或 appSecret 错误，错误码 40001，刷新 token 无法解决，不会重试
+type CredentialError struct {
+	APIError
+}
+
+func (e *CredentialError) Error() string {
+	return fmt.Sprintf("check the corp id and app secret: %s", e.APIError.Error())
+}
+
+// Unwrap 返回原始的 APIError
+func (e *CredentialError) Unwrap() error {
+	return &e.APIError
+}
+
+// ForbiddenError 应用无权调用该接口，错误码 48002，需在管理后台为应用开通权限，不会重试
+type ForbiddenError struct {
+	APIError
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("api not authorized for this app, check its permissions: %s", e.APIError.Error())
+}
+
+// Unwrap 返回原始的 APIError
+func (e *ForbiddenError) Unwrap() error {
+	return &e.APIError
+}
+
+const (
+	errCodeIPNotAllowed      = 60020 // 不在企业可信IP列表
+	errCodeInvalidCredential = 40001 // 不合法的secret参数
+	errCodeAPIForbidden      = 48002 // API接口无权限调用
+	errCodeInvalidMsgType    = 40008 // 不合法的消息类型
+)
+
+// isConfigError 判断错误码是否为配置错误，此类错误重试无效，发送时以 error 返回
+func isConfigError(code int64) bool {
+	return code == errCodeIPNotAllowed || code == errCodeInvalidCredential || code == errCodeAPIForbidden
+}
+
+// fromIPPattern 匹配 60020 错误信息中的来源 IP，如 "not allow to access from your ip, ... from ip: 1.2.3.4, ..."
+var fromIPPattern = regexp.MustCompile(`from ip:\s*([0-9A-Fa-f.:]+)`)
+
+// newAPIError 根据错误码构造对应类型的错误
+func newAPIError(code int64, msg string) error {
+	apiErr := APIError{Code: code, Msg: msg}
+	switch code {
+	case errCodeIPNotAllowed:
+		e := &IPNotAllowedError{APIError: apiErr}
+		if m := fromIPPattern.FindStringSubmatch(msg); m != nil {
+			e.IP = m[1]
+		}
+		return e
+	case errCodeInvalidCredential:
+		return &CredentialError{APIError: apiErr}
+	case errCodeAPIForbidden:
+		return &ForbiddenError{APIError: apiErr}
+	}
+	return &apiErr
+}
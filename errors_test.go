@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	err := newAPIError(60020, "not allow to access from your ip, hint: [1657611234_185_6c9d1fb5bbc7f4a22e5f3d7a1e1c0b0c], from ip: 1.2.3.4, more info at https://open.work.weixin.qq.com/devtool/query?e=60020")
+
+	var ipErr *IPNotAllowedError
+	if !errors.As(err, &ipErr) {
+		t.Fatalf("newAPIError() = %T, want *IPNotAllowedError", err)
+	}
+	if ipErr.IP != "1.2.3.4" {
+		t.Errorf("IPNotAllowedError.IP = %v, want %v", ipErr.IP, "1.2.3.4")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 60020 {
+		t.Errorf("newAPIError() want unwrap to APIError with code 60020, got %v", apiErr)
+	}
+
+	if err := newAPIError(40001, "invalid credential"); errors.As(err, &ipErr) {
+		t.Errorf("newAPIError(40001) = %T, want *APIError", err)
+	}
+}
+
+func TestErrorCodeMessage(t *testing.T) {
+	if got := ErrorCodeMessage(42001); got != "access_token expired（access_token已过期）" {
+		t.Errorf("ErrorCodeMessage(42001) = %v", got)
+	}
+	if got := ErrorCodeMessage(123456); got != "" {
+		t.Errorf("ErrorCodeMessage(123456) = %v, want empty", got)
+	}
+	if got := (&APIError{Code: 45009, Msg: "api freq out of limit"}).Error(); got != "[45009] api call frequency exceeds limit: api freq out of limit" {
+		t.Errorf("APIError.Error() = %v", got)
+	}
+}
+
+func TestNotify_configErrors(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		_, _ = w.Write([]byte(`{"errcode":48002,"errmsg":"api forbidden"}`))
+	})
+	n.SetTokenRetries(3)
+
+	_, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+	var forbidden *ForbiddenError
+	if !errors.As(err, &forbidden) || forbidden.Code != 48002 {
+		t.Errorf("Send() error = %v, want ForbiddenError", err)
+	}
+	if got := atomic.LoadInt32(&sends); got != 1 {
+		t.Errorf("sends = %d, want 1 without retry", got)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":40001,"errmsg":"invalid credential"}`))
+	}))
+	defer server.Close()
+	n = New(t.Name()+"secret", 1, "wrong")
+	n.baseURL = server.URL
+	_, _, err = n.GetToken()
+	var credential *CredentialError
+	if !errors.As(err, &credential) {
+		t.Errorf("GetToken() error = %v, want CredentialError", err)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	receiver := MessageReceiver{ToUser: "@all"}
+	tests := []struct {
+		name     string
+		receiver MessageReceiver
+		message  interface{}
+		want     error
+	}{
+		{name: "NilMessage", receiver: receiver, message: nil, want: ErrNilMessage},
+		{name: "NoReceiver", receiver: MessageReceiver{}, message: Text{Content: "hi"}, want: ErrNoReceiver},
+		{name: "UnsupportedMessageType", receiver: receiver, message: "hi", want: ErrUnsupportedMessageType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.receiver, tt.message, nil, 1); !errors.Is(err, tt.want) {
+				t.Errorf("Marshal() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, _, _, _, err := Unmarshal([]byte(`{"msgtype":"unknown"}`)); !errors.Is(err, ErrUnsupportedMessageType) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnsupportedMessageType)
+	}
+}
+
+func TestNotify_MaxBodySize(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.MaxBodySize = 64
+
+	_, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: strings.Repeat("a", 64)}, nil)
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("Send() error = %v, want %v", err, ErrBodyTooLarge)
+	}
+	if atomic.LoadInt32(&sends) != 0 {
+		t.Errorf("sends = %d, want oversized body not posted", sends)
+	}
+
+	n.MaxBodySize = -1
+	if _, err = n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: strings.Repeat("a", 64)}, nil); err != nil {
+		t.Errorf("Send() error = %v, want no limit when MaxBodySize is negative", err)
+	}
+}
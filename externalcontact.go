@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ExternalContactResult 外部联系人消息发送结果
+type ExternalContactResult struct {
+	FailList []string `json:"fail_list"` // 无效或无法发送的 external_userid 列表
+	MsgID    string   `json:"msgid"`     // 企业群发消息的id，可用于获取群发消息发送结果
+}
+
+// SendToExternalContacts 通过客户联系「企业群发」接口向外部联系人（客户）发送消息。
+// sender 为发送消息的成员 userid，消息需由该成员在客户端确认后才会下发；externalUserIDs 为客户的 external_userid 列表。
+// messages 支持最多一条 Text，以及作为附件的 Image、Video、File、News（每条图文转为链接），附件最多9个，其他类型返回错误。
+// 调用应用需具有客户联系权限
+//
+// 接口文档见：https://developer.work.weixin.qq.com/document/path/92135
+func (n *Notify) SendToExternalContacts(sender string, externalUserIDs []string, messages ...interface{}) (ExternalContactResult, error) {
+	var result ExternalContactResult
+	if len(externalUserIDs) == 0 {
+		return result, errors.New("external user ids can not be empty")
+	}
+	if len(messages) == 0 {
+		return result, ErrNilMessage
+	}
+
+	body := map[string]interface{}{
+		"chat_type":       "single",
+		"external_userid": externalUserIDs,
+		"sender":          sender,
+	}
+	var attachments []map[string]interface{}
+	for _, message := range messages {
+		if message == nil {
+			return result, ErrNilMessage
+		}
+		switch m := message.(type) {
+		case Text:
+			if _, ok := body["text"]; ok {
+				return result, errors.New("only one text message is allowed for external contacts")
+			}
+			body["text"] = m
+		case Image, Video, File:
+			k := m.(MessageKey).key()
+			attachments = append(attachments, map[string]interface{}{"msgtype": k, k: m})
+		case News:
+			for _, article := range m.Articles {
+				attachments = append(attachments, map[string]interface{}{
+					"msgtype": "link",
+					"link": map[string]string{
+						"title":  article.Title,
+						"picurl": article.PicURL,
+						"desc":   article.Description,
+						"url":    article.URL,
+					},
+				})
+			}
+		case MessageKey:
+			return result, fmt.Errorf("%w: %s is not supported for external contacts", ErrUnsupportedMessageType, m.key())
+		default:
+			return result, fmt.Errorf("%w: %T", ErrUnsupportedMessageType, message)
+		}
+	}
+	if len(attachments) > 9 {
+		return result, errors.New("external contact message supports at most 9 attachments")
+	}
+	if len(attachments) > 0 {
+		body["attachments"] = attachments
+	}
+
+	err := n.callAPI(http.MethodPost, "externalcontact/add_msg_template", nil, body, &result)
+	return result, err
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNotify_SendToExternalContacts(t *testing.T) {
+	var body map[string]interface{}
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/externalcontact/add_msg_template" {
+			t.Errorf("request path = %s, want /externalcontact/add_msg_template", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","fail_list":["wmx2"],"msgid":"msg1"}`))
+	})
+
+	result, err := n.SendToExternalContacts("zhangsan", []string{"wmx1", "wmx2"},
+		Text{Content: "双十一优惠"},
+		Image{MediaID: "img1"},
+		News{Articles: []NewsArticle{{Title: "活动", Description: "详情", URL: "https://example.com", PicURL: "https://example.com/a.png"}}},
+	)
+	if err != nil {
+		t.Fatalf("SendToExternalContacts() error = %v", err)
+	}
+	if result.MsgID != "msg1" || !reflect.DeepEqual(result.FailList, []string{"wmx2"}) {
+		t.Errorf("SendToExternalContacts() = %+v, want msgid and fail list", result)
+	}
+	want := map[string]interface{}{
+		"chat_type":       "single",
+		"external_userid": []interface{}{"wmx1", "wmx2"},
+		"sender":          "zhangsan",
+		"text":            map[string]interface{}{"content": "双十一优惠"},
+		"attachments": []interface{}{
+			map[string]interface{}{"msgtype": "image", "image": map[string]interface{}{"media_id": "img1"}},
+			map[string]interface{}{"msgtype": "link", "link": map[string]interface{}{
+				"title": "活动", "desc": "详情", "url": "https://example.com", "picurl": "https://example.com/a.png",
+			}},
+		},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("request body = %v, want %v", body, want)
+	}
+}
+
+func TestNotify_SendToExternalContactsErrors(t *testing.T) {
+	var calls int
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	users := []string{"wmx1"}
+	attachments := make([]interface{}, 10)
+	for i := range attachments {
+		attachments[i] = File{MediaID: "f"}
+	}
+
+	if _, err := n.SendToExternalContacts("zhangsan", users, Text{Content: "a"}, Text{Content: "b"}); err == nil {
+		t.Errorf("SendToExternalContacts() want error for more than one text")
+	}
+	if _, err := n.SendToExternalContacts("zhangsan", users, attachments...); err == nil {
+		t.Errorf("SendToExternalContacts() want error for more than 9 attachments")
+	}
+	if _, err := n.SendToExternalContacts("zhangsan", users, attachments[:9]...); err != nil {
+		t.Errorf("SendToExternalContacts() error = %v, want 9 attachments allowed", err)
+	}
+	if _, err := n.SendToExternalContacts("zhangsan", users, Markdown{Content: "**hi**"}); !errors.Is(err, ErrUnsupportedMessageType) {
+		t.Errorf("SendToExternalContacts() error = %v, want %v", err, ErrUnsupportedMessageType)
+	}
+	if _, err := n.SendToExternalContacts("zhangsan", users, "hi"); !errors.Is(err, ErrUnsupportedMessageType) {
+		t.Errorf("SendToExternalContacts() error = %v, want %v", err, ErrUnsupportedMessageType)
+	}
+	if _, err := n.SendToExternalContacts("zhangsan", users); !errors.Is(err, ErrNilMessage) {
+		t.Errorf("SendToExternalContacts() error = %v, want %v", err, ErrNilMessage)
+	}
+	if calls != 1 {
+		t.Errorf("requests = %d, want only the valid message sent", calls)
+	}
+}
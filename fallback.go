@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FallbackError 应用消息发送失败，消息摘要已通过群机器人 webhook 送达，Err 为应用消息的发送错误
+type FallbackError struct {
+	Err error
+}
+
+func (e *FallbackError) Error() string {
+	return fmt.Sprintf("sent via fallback webhook: %v", e.Err)
+}
+
+// Unwrap 返回应用消息的发送错误
+func (e *FallbackError) Unwrap() error {
+	return e.Err
+}
+
+// SetFallbackWebhook 设置群机器人 webhook 的 key，MessageOptions.FallbackWebhook 为 true 的消息发送失败时，
+// 将消息的文本摘要发送到该群。key 为空时关闭降级
+func (n *Notify) SetFallbackWebhook(key string) {
+	n.fallbackKey = key
+}
+
+// fallback 应用消息发送失败时通过 webhook 发送文本摘要，成功时返回 FallbackError，失败时在原错误中附带 webhook 的错误。
+// 发送失败可能是 ctx 超时所致，webhook 请求不使用 ctx
+func (n *Notify) fallback(message interface{}, options *MessageOptions, result MessageResult, err error) (MessageResult, error) {
+	if n.fallbackKey == "" || options == nil || !options.FallbackWebhook || (err == nil && result.ErrorCode == 0) {
+		return result, err
+	}
+	if err == nil {
+		err = newAPIError(result.ErrorCode, result.ErrorMsg)
+	}
+	content := fmt.Sprintf("%s\n（应用消息发送失败：%v）", summarize(message), err)
+	if fbErr := n.sendWebhook(content); fbErr != nil {
+		return result, fmt.Errorf("%w (fallback webhook error: %v)", err, fbErr)
+	}
+	return result, &FallbackError{Err: err}
+}
+
+// sendWebhook 通过群机器人 webhook 发送文本消息
+func (n *Notify) sendWebhook(content string) error {
+	body, err := n.codec.Marshal(map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": content},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook message error: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, n.baseURL+"/webhook/send?key="+url.QueryEscape(n.fallbackKey), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook create request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := n.do(n.client(), req)
+	if err != nil {
+		return fmt.Errorf("webhook request error: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var result apiResult
+	if err = n.decode(res.Body, &result); err != nil {
+		return fmt.Errorf("webhook result decode error: %w", err)
+	}
+	if result.ErrorCode != 0 {
+		return newAPIError(result.ErrorCode, result.ErrorMsg)
+	}
+	return nil
+}
+
+// summarize 返回消息的文本摘要
+func summarize(message interface{}) string {
+	switch m := message.(type) {
+	case Text:
+		return m.Content
+	case Markdown:
+		return m.Content
+	case TextCard:
+		return strings.Join([]string{m.Title, m.Description, m.URL}, "\n")
+	case TaskCard:
+		return strings.Join([]string{m.Title, m.Description}, "\n")
+	case News:
+		lines := make([]string, 0, len(m.Articles))
+		for _, a := range m.Articles {
+			lines = append(lines, a.Title+" "+a.URL)
+		}
+		return strings.Join(lines, "\n")
+	case MpNews:
+		lines := make([]string, 0, len(m.Articles))
+		for _, a := range m.Articles {
+			lines = append(lines, a.Title)
+		}
+		return strings.Join(lines, "\n")
+	case MessageKey:
+		return fmt.Sprintf("[%s] 消息", m.key())
+	}
+	return fmt.Sprintf("%v", message)
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNotify_SetFallbackWebhook(t *testing.T) {
+	var webhook string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/message/send":
+			_, _ = w.Write([]byte(`{"errcode":60020,"errmsg":"not allow to access from your ip, from ip: 1.2.3.4"}`))
+		case "/webhook/send":
+			if r.URL.Query().Get("key") != "k1" {
+				_, _ = w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+				return
+			}
+			b, _ := io.ReadAll(r.Body)
+			webhook = string(b)
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	})
+	receiver := MessageReceiver{ToUser: "@all"}
+	message := TextCard{Title: "DB down", Description: "db1", URL: "https://example.com"}
+
+	// 未开启降级
+	n.SetFallbackWebhook("k1")
+	if _, err := n.Send(receiver, message, nil); err == nil || webhook != "" {
+		t.Fatalf("Send() error = %v, webhook = %q, want error without fallback", err, webhook)
+	}
+
+	options := &MessageOptions{FallbackWebhook: true}
+	_, err := n.Send(receiver, message, options)
+	var fbErr *FallbackError
+	var ipErr *IPNotAllowedError
+	if !errors.As(err, &fbErr) || !errors.As(err, &ipErr) {
+		t.Fatalf("Send() error = %v, want FallbackError wrapping IPNotAllowedError", err)
+	}
+	if !strings.Contains(webhook, `"msgtype":"text"`) || !strings.Contains(webhook, "DB down") {
+		t.Errorf("webhook body = %s, want text summary", webhook)
+	}
+
+	n.SetFallbackWebhook("bad")
+	if _, err = n.Send(receiver, message, options); errors.As(err, &fbErr) || !errors.As(err, &ipErr) {
+		t.Errorf("Send() error = %v, want original error when webhook fails", err)
+	}
+}
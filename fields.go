@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+)
+
+// Field 告警消息中的键值对
+type Field struct {
+	Key   string
+	Value string
+}
+
+// severityColors 严重级别到 markdown 字体颜色的映射，warning 为橙红色，comment 为灰色，info 为绿色
+var severityColors = map[string]string{
+	"critical": "warning",
+	"fatal":    "warning",
+	"error":    "warning",
+	"high":     "warning",
+	"warning":  "comment",
+	"warn":     "comment",
+	"medium":   "comment",
+	"info":     "info",
+	"low":      "info",
+	"ok":       "info",
+	"resolved": "info",
+}
+
+// SendFields 将标题及键值对格式化为 markdown 消息并发送，标题加粗，每个字段一行 **key**: value。
+// key 为 severity 或 level 时按级别着色，以 http:// 或 https:// 开头的值格式化为链接
+func (n *Notify) SendFields(receiver MessageReceiver, title string, fields []Field, options *MessageOptions) (MessageResult, error) {
+	if title == "" && len(fields) == 0 {
+		return MessageResult{}, errors.New("fields message can not be empty")
+	}
+	return n.Send(receiver, FieldsMarkdown(title, fields), options)
+}
+
+// FieldsMarkdown 返回 SendFields 格式化后的 markdown 消息
+func FieldsMarkdown(title string, fields []Field) Markdown {
+	var b strings.Builder
+	if title != "" {
+		b.WriteString("**" + title + "**\n")
+	}
+	for _, f := range fields {
+		b.WriteString("**" + f.Key + "**: " + formatFieldValue(f) + "\n")
+	}
+	return Markdown{Content: strings.TrimSuffix(b.String(), "\n")}
+}
+
+// formatFieldValue 格式化字段值
+func formatFieldValue(f Field) string {
+	if strings.HasPrefix(f.Value, "http://") || strings.HasPrefix(f.Value, "https://") {
+		return "[" + f.Value + "](" + f.Value + ")"
+	}
+	key := strings.ToLower(f.Key)
+	if key == "severity" || key == "level" {
+		if color, ok := severityColors[strings.ToLower(f.Value)]; ok {
+			return `<font color="` + color + `">` + f.Value + "</font>"
+		}
+	}
+	return f.Value
+}
@@ -0,0 +1,18 @@
+package notify
+
+import "testing"
+
+func TestFieldsMarkdown(t *testing.T) {
+	got := FieldsMarkdown("DB down", []Field{
+		{Key: "service", Value: "db1"},
+		{Key: "Severity", Value: "critical"},
+		{Key: "runbook", Value: "https://example.com/runbook"},
+	})
+	want := "**DB down**\n" +
+		"**service**: db1\n" +
+		`**Severity**: <font color="warning">critical</font>` + "\n" +
+		"**runbook**: [https://example.com/runbook](https://example.com/runbook)"
+	if got.Content != want {
+		t.Errorf("FieldsMarkdown() = %q, want %q", got.Content, want)
+	}
+}
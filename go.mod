@@ -6,4 +6,5 @@ require (
 	github.com/spf13/cobra v1.3.0
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.10.0
+	golang.org/x/sync v0.1.0
 )
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNoHeartbeat 心跳尚未完成，未调用 StartHeartbeat 或第一次心跳仍在发送中
+var ErrNoHeartbeat = errors.New("no heartbeat completed yet")
+
+// heartbeatContent 心跳消息内容
+const heartbeatContent = "notify heartbeat"
+
+// heartbeat 心跳状态
+type heartbeat struct {
+	mu          sync.Mutex
+	stop        chan struct{}
+	lastSuccess time.Time // 最近一次成功的时间
+	err         error     // 最近一次心跳的错误，成功时为 nil
+}
+
+// StartHeartbeat 每隔 interval 向 testUser 发送一条文本消息以检测发送链路，启动时立即发送一次，
+// 发送失败、返回错误码或 testUser 无效均视为失败，结果通过 LastHeartbeat 获取。
+// 再次调用时停止之前的心跳，返回的 stop 用于停止心跳，多次调用安全。interval 不大于 0 或 testUser 为空时不启动
+func (n *Notify) StartHeartbeat(interval time.Duration, testUser string) (stop func()) {
+	n.heartbeat.mu.Lock()
+	if n.heartbeat.stop != nil {
+		close(n.heartbeat.stop)
+		n.heartbeat.stop = nil
+	}
+	if interval <= 0 || testUser == "" {
+		n.heartbeat.mu.Unlock()
+		return func() {}
+	}
+	done := make(chan struct{})
+	n.heartbeat.stop = done
+	n.heartbeat.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n.beat(interval, testUser, done)
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		n.heartbeat.mu.Lock()
+		defer n.heartbeat.mu.Unlock()
+		if n.heartbeat.stop == done {
+			close(done)
+			n.heartbeat.stop = nil
+		}
+	}
+}
+
+// LastHeartbeat 返回最近一次心跳成功的时间及最近一次心跳的错误，最近一次心跳成功时错误为 nil。
+// 尚未完成心跳时返回 ErrNoHeartbeat
+func (n *Notify) LastHeartbeat() (time.Time, error) {
+	n.heartbeat.mu.Lock()
+	defer n.heartbeat.mu.Unlock()
+	if n.heartbeat.lastSuccess.IsZero() && n.heartbeat.err == nil {
+		return time.Time{}, ErrNoHeartbeat
+	}
+	return n.heartbeat.lastSuccess, n.heartbeat.err
+}
+
+// beat 发送一次心跳并记录结果，发送超时为 interval，心跳已停止时不记录
+func (n *Notify) beat(interval time.Duration, testUser string, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), interval)
+	defer cancel()
+	result, err := n.SendContext(ctx, MessageReceiver{ToUser: testUser}, Text{Content: heartbeatContent}, nil)
+	if err == nil && result.ErrorCode != 0 {
+		err = newAPIError(result.ErrorCode, result.ErrorMsg)
+	}
+	if err == nil && result.HasInvalid() {
+		err = fmt.Errorf("heartbeat user %s is invalid", testUser)
+	}
+	if err != nil {
+		err = fmt.Errorf("heartbeat error: %w", err)
+	}
+
+	n.heartbeat.mu.Lock()
+	defer n.heartbeat.mu.Unlock()
+	if n.heartbeat.stop != done {
+		return
+	}
+	n.heartbeat.err = err
+	if err == nil {
+		n.heartbeat.lastSuccess = n.now()
+	}
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_StartHeartbeat(t *testing.T) {
+	var calls int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","invaliduser":"monitor"}`))
+	})
+
+	if _, err := n.LastHeartbeat(); !errors.Is(err, ErrNoHeartbeat) {
+		t.Fatalf("LastHeartbeat() error = %v, want %v", err, ErrNoHeartbeat)
+	}
+
+	stop := n.StartHeartbeat(20*time.Millisecond, "monitor")
+	defer stop()
+	var last time.Time
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var err error
+		last, err = n.LastHeartbeat()
+		if err != nil && !errors.Is(err, ErrNoHeartbeat) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("LastHeartbeat() error = %v, want heartbeat failure for invalid user", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if last.IsZero() {
+		t.Errorf("LastHeartbeat() = zero time, want time of first successful heartbeat")
+	}
+
+	stop()
+	got := atomic.LoadInt32(&calls)
+	time.Sleep(60 * time.Millisecond)
+	if after := atomic.LoadInt32(&calls); after > got+1 {
+		t.Errorf("sends after stop = %d, want heartbeat stopped", after-got)
+	}
+}
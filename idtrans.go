@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIDTransUnsupported 消息类型不支持 id 转译，开启 EnableIDTrans 不会生效
+var ErrIDTransUnsupported = errors.New("message type does not support id translation")
+
+// idTransFields 支持 id 转译的消息类型及转译的字段，文本中的 $userName=userid$、$departmentName=partyid$ 会被转译为名称
+var idTransFields = map[string][]string{
+	"text":               {"content"},
+	"textcard":           {"title", "description"},
+	"news":               {"title", "description"},
+	"mpnews":             {"title", "digest", "content"},
+	"miniprogram_notice": {"title", "description", "content_item"},
+	"taskcard":           {"title", "description"},
+}
+
+// IDTransFields 返回消息类型 msgType 中支持 id 转译的字段，不支持 id 转译的类型（如 image、file、markdown）返回 nil
+func IDTransFields(msgType string) []string {
+	return append([]string(nil), idTransFields[msgType]...)
+}
+
+// validateIDTrans 校验开启 id 转译时消息类型是否支持，RawMessage 不做校验
+func validateIDTrans(message MessageKey, options *MessageOptions) error {
+	if options == nil || !options.EnableIDTrans {
+		return nil
+	}
+	if _, ok := message.(RawMessage); ok {
+		return nil
+	}
+	if _, ok := idTransFields[message.key()]; !ok {
+		return fmt.Errorf("%w: %s", ErrIDTransUnsupported, message.key())
+	}
+	return nil
+}
+
+// dropDefaultIDTrans 默认配置开启的 id 转译仅对支持的消息类型生效，其他类型发送时去除 enable_id_trans，
+// 不返回 ErrIDTransUnsupported。options 为本次发送的配置，由其开启时仍由 validateIDTrans 返回错误
+func dropDefaultIDTrans(message interface{}, options, merged *MessageOptions) *MessageOptions {
+	if merged == nil || !merged.EnableIDTrans || (options != nil && options.EnableIDTrans) {
+		return merged
+	}
+	k, ok := message.(MessageKey)
+	if !ok {
+		return merged
+	}
+	if _, ok = idTransFields[k.key()]; ok {
+		return merged
+	}
+	if _, ok = message.(RawMessage); ok {
+		return merged
+	}
+	o := *merged
+	o.EnableIDTrans = false
+	return &o
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestValidateIDTrans(t *testing.T) {
+	receiver := MessageReceiver{ToUser: "zhangsan"}
+	options := &MessageOptions{EnableIDTrans: true}
+
+	if _, err := Marshal(receiver, Text{Content: "$userName=zhangsan$"}, options, 1); err != nil {
+		t.Errorf("Marshal() text error = %v, want no error", err)
+	}
+	if _, err := Marshal(receiver, Image{MediaID: "media"}, options, 1); !errors.Is(err, ErrIDTransUnsupported) {
+		t.Errorf("Marshal() image error = %v, want %v", err, ErrIDTransUnsupported)
+	}
+	if _, err := Marshal(receiver, Image{MediaID: "media"}, nil, 1); err != nil {
+		t.Errorf("Marshal() image without id trans error = %v, want no error", err)
+	}
+
+	if got, want := IDTransFields("textcard"), []string{"title", "description"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IDTransFields() = %v, want %v", got, want)
+	}
+	if got := IDTransFields("markdown"); got != nil {
+		t.Errorf("IDTransFields() = %v, want nil", got)
+	}
+}
+
+func TestNotify_DefaultIDTrans(t *testing.T) {
+	var bodies []map[string]interface{}
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.DefaultOptions = &MessageOptions{EnableIDTrans: true}
+	receiver := MessageReceiver{ToUser: "zhangsan"}
+
+	if _, err := n.Send(receiver, Image{MediaID: "m"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want default id translation ignored for image", err)
+	}
+	if _, err := n.Send(receiver, Text{Content: "$userName=zhangsan$"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if _, ok := bodies[0]["enable_id_trans"]; ok {
+		t.Errorf("image body = %v, want no enable_id_trans", bodies[0])
+	}
+	if bodies[1]["enable_id_trans"] != float64(1) {
+		t.Errorf("text body = %v, want enable_id_trans 1", bodies[1])
+	}
+
+	if _, err := n.Send(receiver, Image{MediaID: "m"}, &MessageOptions{EnableIDTrans: true}); !errors.Is(err, ErrIDTransUnsupported) {
+		t.Errorf("Send() error = %v, want %v when requested per call", err, ErrIDTransUnsupported)
+	}
+	if n.DefaultOptions.EnableIDTrans != true {
+		t.Errorf("DefaultOptions modified = %+v", n.DefaultOptions)
+	}
+}
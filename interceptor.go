@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// SendFunc 发送消息体 msgBody 并返回发送结果
+type SendFunc func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error)
+
+// SendInterceptor 包装 SendFunc，可用于日志、监控、修改消息体或直接返回结果而不调用 next
+type SendInterceptor func(next SendFunc) SendFunc
+
+// Use 注册拦截器，先注册的拦截器位于外层，最内层为实际的发送请求
+func (n *Notify) Use(interceptor SendInterceptor) {
+	n.interceptors = append(n.interceptors, interceptor)
+}
+
+// SendInfo 单次 Send 调用的执行信息，通过 OnSend 注册的回调获取
+type SendInfo struct {
+	MsgType        string        // 消息类型
+	CorrelationID  string        // 通过 WithCorrelationID 设置的关联 id，不会发送给企业微信
+	TokenRefreshed bool          // 本次发送是否刷新了 access_token，否则使用的是缓存的 token
+	TokenRetried   bool          // 是否因 access_token 过期或无效触发了重试
+	Attempts       int           // 发送消息请求的次数，包括 token 失效重试，1 表示首次即成功
+	TestMode       bool          // 是否为测试模式下的记录，未实际发送，见 EnableTestMode
+	Timing         SendTiming    // 发送耗时
+	Result         MessageResult // 发送结果
+	Err            error         // 发送错误
+}
+
+// SendTiming 单次发送的耗时分解，包括 token 失效重试
+type SendTiming struct {
+	TokenAcquire time.Duration // 获取 access_token 的耗时，包括刷新
+	HTTP         time.Duration // 发送消息请求的耗时
+	Total        time.Duration // 包括拦截器在内的总耗时
+}
+
+type sendInfoKey struct{}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID 返回携带关联 id 的 ctx，用于 SendContext。关联 id 会出现在 SendInfo 中，
+// 拦截器可通过 CorrelationID 获取，便于将同一告警的发送、token 刷新及重试日志串联起来
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID 返回 ctx 中的关联 id，未设置时返回空字符串
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// sendInfoFrom 获取 ctx 中当前发送的执行信息，不存在时返回一个不会被读取的新对象
+func sendInfoFrom(ctx context.Context) *SendInfo {
+	if info, ok := ctx.Value(sendInfoKey{}).(*SendInfo); ok {
+		return info
+	}
+	return &SendInfo{}
+}
+
+// OnSend 设置每次 Send 完成后的回调，可用于日志、监控及分析发送耗时
+func (n *Notify) OnSend(hook func(info SendInfo)) {
+	n.onSend = hook
+}
+
+// notifySend 统计发送成功数，填充发送结果并调用 OnSend 回调
+func (n *Notify) notifySend(info *SendInfo, result MessageResult, err error) {
+	if err == nil && result.ErrorCode == 0 {
+		n.countSend()
+	}
+	if n.onSend == nil {
+		return
+	}
+	info.Result = result
+	info.Err = err
+	n.onSend(*info)
+}
+
+// sendChain 按注册顺序组装拦截器
+func (n *Notify) sendChain() SendFunc {
+	var send SendFunc = n.sendInternal
+	for i := len(n.interceptors) - 1; i >= 0; i-- {
+		send = n.interceptors[i](send)
+	}
+	return send
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNotify_Use(t *testing.T) {
+	n := New("corp", 1, "secret")
+
+	var calls []string
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			calls = append(calls, "outer")
+			msgBody["safe"] = 1
+			return next(ctx, msgBody)
+		}
+	})
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			calls = append(calls, "inner")
+			if msgBody["safe"] != 1 {
+				t.Errorf("inner interceptor got safe = %v, want 1", msgBody["safe"])
+			}
+			return MessageResult{ErrorCode: 0, ErrorMsg: "short-circuit"}, nil
+		}
+	})
+
+	got, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if got.ErrorMsg != "short-circuit" {
+		t.Errorf("Send() got = %v, want short-circuit result", got)
+	}
+	if want := []string{"outer", "inner"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("interceptor calls = %v, want %v", calls, want)
+	}
+}
+
+func TestNotify_OnSend(t *testing.T) {
+	var calls int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 3 {
+			_, _ = w.Write([]byte(`{"errcode":42001,"errmsg":"access_token expired"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	var infos []SendInfo
+	n.OnSend(func(info SendInfo) {
+		if info.Timing.Total <= 0 || info.Timing.HTTP <= 0 || info.Timing.Total < info.Timing.HTTP+info.Timing.TokenAcquire {
+			t.Errorf("OnSend() timing = %+v, want total covering token and http", info.Timing)
+		}
+		info.Timing = SendTiming{}
+		infos = append(infos, info)
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+			t.Fatalf("Send() error = %v, want no error", err)
+		}
+	}
+
+	want := []SendInfo{
+		{MsgType: "text", TokenRefreshed: true, Attempts: 1, Result: MessageResult{ErrorMsg: "ok"}},
+		{MsgType: "text", Attempts: 1, Result: MessageResult{ErrorMsg: "ok"}},
+		{MsgType: "text", TokenRefreshed: true, TokenRetried: true, Attempts: 2, Result: MessageResult{ErrorMsg: "ok"}},
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Errorf("OnSend() infos = %+v, want %+v", infos, want)
+	}
+}
+
+func TestNotify_CorrelationID(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if b, _ := io.ReadAll(r.Body); strings.Contains(string(b), "incident-1") {
+			t.Errorf("request body = %s, want no correlation id", b)
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	var seen string
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			seen = CorrelationID(ctx)
+			return next(ctx, msgBody)
+		}
+	})
+	var info SendInfo
+	n.OnSend(func(i SendInfo) {
+		info = i
+	})
+
+	ctx := WithCorrelationID(context.Background(), "incident-1")
+	if _, err := n.SendContext(ctx, MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("SendContext() error = %v, want no error", err)
+	}
+	if seen != "incident-1" || info.CorrelationID != "incident-1" {
+		t.Errorf("correlation id in interceptor = %q, in SendInfo = %q, want incident-1", seen, info.CorrelationID)
+	}
+}
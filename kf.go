@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Menu 微信客服的菜单消息（msgmenu），客户点击菜单项后触发回调或跳转，仅用于 SendKF，Send 等应用消息接口返回 ErrUnsupportedMessageType
+type Menu struct {
+	HeadContent string     `json:"head_content,omitempty"` // 非必填。起始文本，不超过1024个字节
+	List        []MenuItem `json:"list"`                   // 菜单项，不超过10个
+	TailContent string     `json:"tail_content,omitempty"` // 非必填。结束文本，不超过1024个字节
+}
+
+func (t Menu) key() string {
+	return "msgmenu"
+}
+
+// MenuItem 菜单项，Type 为 click、view、miniprogram 或 text，对应字段非空
+type MenuItem struct {
+	Type        string           `json:"type"`
+	Click       *MenuClick       `json:"click,omitempty"`
+	View        *MenuView        `json:"view,omitempty"`
+	MiniProgram *MenuMiniProgram `json:"miniprogram,omitempty"`
+	Text        *MenuText        `json:"text,omitempty"`
+}
+
+// MenuClick 回复菜单，客户点击后以 Content 回复消息并携带 ID 触发回调
+type MenuClick struct {
+	ID      string `json:"id,omitempty"` // 非必填。菜单id，不超过128个字节
+	Content string `json:"content"`      // 菜单显示内容，不超过128个字节
+}
+
+// MenuView 超链接菜单
+type MenuView struct {
+	URL     string `json:"url"`     // 点击后跳转的链接，不超过2048个字节
+	Content string `json:"content"` // 菜单显示内容，不超过1024个字节
+}
+
+// MenuMiniProgram 小程序菜单
+type MenuMiniProgram struct {
+	AppID    string `json:"appid"`    // 小程序appid
+	PagePath string `json:"pagepath"` // 点击后进入的小程序页面
+	Content  string `json:"content"`  // 菜单显示内容，不超过1024个字节
+}
+
+// MenuText 文本，不可点击
+type MenuText struct {
+	Content   string `json:"content"`              // 文本内容，支持换行
+	NoNewline int    `json:"no_newline,omitempty"` // 非必填。1 表示内容后不换行
+}
+
+// ClickMenuItem 创建回复菜单项
+func ClickMenuItem(id, content string) MenuItem {
+	return MenuItem{Type: "click", Click: &MenuClick{ID: id, Content: content}}
+}
+
+// ViewMenuItem 创建超链接菜单项
+func ViewMenuItem(url, content string) MenuItem {
+	return MenuItem{Type: "view", View: &MenuView{URL: url, Content: content}}
+}
+
+// MiniProgramMenuItem 创建小程序菜单项
+func MiniProgramMenuItem(appID, pagePath, content string) MenuItem {
+	return MenuItem{Type: "miniprogram", MiniProgram: &MenuMiniProgram{AppID: appID, PagePath: pagePath, Content: content}}
+}
+
+// Validate 校验菜单项数量
+func (t Menu) Validate() error {
+	if len(t.List) == 0 {
+		return errors.New("menu list can not be empty")
+	}
+	if len(t.List) > 10 {
+		return errors.New("menu supports at most 10 items")
+	}
+	return nil
+}
+
+// SendKF 通过微信客服账号 openKFID 向客户 externalUserID 发送消息，返回消息id。
+// 支持 Text、Image、Voice、Video、File 及 Menu，需使用微信客服的 secret 创建客户端，客户48小时内主动发送过消息才可发送。
+// 接口文档见：https://developer.work.weixin.qq.com/document/path/94677
+func (n *Notify) SendKF(openKFID, externalUserID string, message interface{}) (string, error) {
+	if openKFID == "" || externalUserID == "" {
+		return "", errors.New("open kf id and external user id can not be empty")
+	}
+	switch m := message.(type) {
+	case nil:
+		return "", ErrNilMessage
+	case Menu:
+		if err := m.Validate(); err != nil {
+			return "", err
+		}
+	case Text, Image, Voice, Video, File:
+	case MessageKey:
+		return "", fmt.Errorf("%w: %s is not supported for kf", ErrUnsupportedMessageType, m.key())
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnsupportedMessageType, message)
+	}
+
+	k := message.(MessageKey).key()
+	body := map[string]interface{}{
+		"touser":    externalUserID,
+		"open_kfid": openKFID,
+		"msgtype":   k,
+		k:           message,
+	}
+	var result struct {
+		MsgID string `json:"msgid"`
+	}
+	err := n.callAPI(http.MethodPost, "kf/send_msg", nil, body, &result)
+	return result.MsgID, err
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestNotify_SendKF(t *testing.T) {
+	var got string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","msgid":"kf-1"}`))
+	})
+
+	menu := Menu{HeadContent: "您对本次服务是否满意呢？", List: []MenuItem{ClickMenuItem("101", "满意"), ViewMenuItem("https://work.weixin.qq.com", "查看详情")}}
+	msgID, err := n.SendKF("wkAJ2GCAAASSm4_FhToWMFea0xAFfd3Q", "wmAJ2GCAAAme1XQRC-NI-q0_ZM9ukoAw", menu)
+	if err != nil {
+		t.Fatalf("SendKF() error = %v, want no error", err)
+	}
+	want := `{"msgmenu":{"head_content":"您对本次服务是否满意呢？","list":[{"type":"click","click":{"id":"101","content":"满意"}},{"type":"view","view":{"url":"https://work.weixin.qq.com","content":"查看详情"}}]},"msgtype":"msgmenu","open_kfid":"wkAJ2GCAAASSm4_FhToWMFea0xAFfd3Q","touser":"wmAJ2GCAAAme1XQRC-NI-q0_ZM9ukoAw"}`
+	if msgID != "kf-1" || got != want {
+		t.Errorf("SendKF() msgid = %q, body = %s, want kf-1, %s", msgID, got, want)
+	}
+
+	if _, err = n.SendKF("kf", "user", Menu{}); err == nil {
+		t.Errorf("SendKF() empty menu want error")
+	}
+	if _, err = n.SendKF("kf", "user", Markdown{Content: "hi"}); err == nil {
+		t.Errorf("SendKF() markdown want error")
+	}
+	if _, err = n.SendKF("kf", "user", nil); !errors.Is(err, ErrNilMessage) {
+		t.Errorf("SendKF() error = %v, want %v", err, ErrNilMessage)
+	}
+}
+
+func TestNotify_SendMenuUnsupported(t *testing.T) {
+	var calls int
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	menu := Menu{List: []MenuItem{ClickMenuItem("101", "满意")}}
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, menu, nil); !errors.Is(err, ErrUnsupportedMessageType) {
+		t.Errorf("Send() error = %v, want %v", err, ErrUnsupportedMessageType)
+	}
+	if calls != 0 {
+		t.Errorf("requests = %d, want menu not sent as app message", calls)
+	}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// defaultCacheLockTimeout CacheLockTimeout 的默认值
+	defaultCacheLockTimeout = time.Second
+	// staleLockAge 锁文件超过该时长未释放时视为持有进程已崩溃，直接删除
+	staleLockAge = 30 * time.Second
+	// lockRetryInterval 等待锁时的重试间隔
+	lockRetryInterval = 10 * time.Millisecond
+)
+
+// ErrCacheLockTimeout 等待缓存文件锁超时
+var ErrCacheLockTimeout = errors.New("cache file lock timeout")
+
+// lockCacheFile 创建缓存文件的锁文件，多个进程共享同一缓存文件时串行读写，ctx 结束时返回 ErrCacheLockTimeout。
+// 锁文件超过 staleLockAge 未释放时视为残留并删除，避免崩溃的进程阻塞其他进程
+func (n *Notify) lockCacheFile(ctx context.Context) (unlock func(), err error) {
+	path := n.CacheFilePath + ".lock"
+	for {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create cache lock file error: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %v", ErrCacheLockTimeout, ctx.Err())
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// cacheLockContext 返回等待缓存文件锁的 ctx，在 ctx 的基础上增加 CacheLockTimeout 超时
+func (n *Notify) cacheLockContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := n.CacheLockTimeout
+	if timeout <= 0 {
+		timeout = defaultCacheLockTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
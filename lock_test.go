@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNotify_SaveTokenCacheLock(t *testing.T) {
+	n := New(t.Name(), 1, "secret")
+	n.TokenPersist = true
+	n.SetCacheFilePath(filepath.Join(t.TempDir(), ".notify"))
+	n.Token = "token"
+	n.TokenExpiresAt = time.Now().Add(time.Hour).Unix()
+	n.CacheLockTimeout = 50 * time.Millisecond
+
+	lock := n.CacheFilePath + ".lock"
+	if err := os.WriteFile(lock, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.saveTokenCache(context.Background()); !errors.Is(err, ErrCacheLockTimeout) {
+		t.Errorf("saveTokenCache() error = %v, want %v", err, ErrCacheLockTimeout)
+	}
+
+	// 残留的锁文件被删除
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.saveTokenCache(context.Background()); err != nil {
+		t.Fatalf("saveTokenCache() error = %v, want no error", err)
+	}
+	if _, err := os.Stat(lock); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("saveTokenCache() lock file not released, stat error = %v", err)
+	}
+}
+
+func TestNotify_LoadTokenCacheLock(t *testing.T) {
+	var refreshed int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {})
+	n.TokenPersist = true
+	n.SetCacheFilePath(filepath.Join(t.TempDir(), ".notify"))
+	n.CacheLockTimeout = time.Minute
+	n.SetSecretProvider(func() (string, error) {
+		refreshed++
+		return "secret", nil
+	})
+	if err := os.WriteFile(n.CacheFilePath, []byte(`{"Token":"cached","TokenExpiresAt":4102444800}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他进程写入缓存文件时不读取，ctx 结束后返回
+	lock := n.CacheFilePath + ".lock"
+	if err := os.WriteFile(lock, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := n.loadTokenCache(ctx); !errors.Is(err, ErrCacheLockTimeout) {
+		t.Errorf("loadTokenCache() error = %v, want %v", err, ErrCacheLockTimeout)
+	}
+	if err := n.saveTokenCache(ctx); !errors.Is(err, ErrCacheLockTimeout) {
+		t.Errorf("saveTokenCache() error = %v, want %v", err, ErrCacheLockTimeout)
+	}
+
+	// 锁释放后读取其他进程写入的 token，不再重新获取
+	if err := os.Remove(lock); err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := n.GetTokenContext(context.Background())
+	if err != nil || token != "cached" || refreshed != 0 {
+		t.Errorf("GetTokenContext() = %q, %v after %d refreshes, want cached token", token, err, refreshed)
+	}
+}
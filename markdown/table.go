@@ -0,0 +1,49 @@
+// Package markdown 提供构造企业微信 markdown 消息内容的辅助函数
+package markdown
+
+import "strings"
+
+// cellReplacer 转义单元格中的竖线，并将换行替换为空格以免破坏表格
+var cellReplacer = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+// Table 生成 GitHub 风格的 markdown 表格，用于 markdown_v2 消息，普通 markdown 消息不支持表格。
+// notify 包没有 markdown_v2 消息类型，需通过 RawMessage 发送：
+//
+//	notify.RawMessage{MsgType: "markdown_v2", Content: map[string]string{"content": markdown.Table(headers, rows)}}
+//
+// 列数取表头及各行的最大值，不足的单元格以空字符串补齐，单元格中的竖线会被转义
+func Table(headers []string, rows [][]string) string {
+	columns := len(headers)
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	writeRow(&b, headers, columns)
+	b.WriteString("\n|")
+	for i := 0; i < columns; i++ {
+		b.WriteString(" --- |")
+	}
+	for _, row := range rows {
+		b.WriteString("\n")
+		writeRow(&b, row, columns)
+	}
+	return b.String()
+}
+
+// writeRow 写入一行，不足 columns 列时补齐空单元格
+func writeRow(b *strings.Builder, cells []string, columns int) {
+	b.WriteString("|")
+	for i := 0; i < columns; i++ {
+		var cell string
+		if i < len(cells) {
+			cell = cellReplacer.Replace(strings.TrimSpace(cells[i]))
+		}
+		b.WriteString(" " + cell + " |")
+	}
+}
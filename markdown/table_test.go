@@ -0,0 +1,39 @@
+package markdown
+
+import "testing"
+
+func TestTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		rows    [][]string
+		want    string
+	}{
+		{
+			name:    "Simple",
+			headers: []string{"主机", "状态"},
+			rows:    [][]string{{"web-1", "正常"}, {"web-2", "宕机"}},
+			want:    "| 主机 | 状态 |\n| --- | --- |\n| web-1 | 正常 |\n| web-2 | 宕机 |",
+		},
+		{
+			name:    "Ragged",
+			headers: []string{"sql"},
+			rows:    [][]string{{"select 1", "12ms"}, {}},
+			want:    "| sql |  |\n| --- | --- |\n| select 1 | 12ms |\n|  |  |",
+		},
+		{
+			name:    "Escape",
+			headers: []string{"a|b"},
+			rows:    [][]string{{"line1\nline2"}},
+			want:    "| a\\|b |\n| --- |\n| line1 line2 |",
+		},
+		{name: "Empty", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Table(tt.headers, tt.rows); got != tt.want {
+				t.Errorf("Table() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
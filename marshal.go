@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// messageTypes 已支持的消息类型，按 msgtype 索引
+	messageTypes = make(map[string]reflect.Type)
+	// messageTypeKeys 已支持的 msgtype，按注册顺序排列
+	messageTypeKeys []string
+)
+
+// messageTypeLabels msgtype 的中文名称
+var messageTypeLabels = map[string]string{
+	"text":               "文本消息",
+	"image":              "图片消息",
+	"voice":              "语音消息",
+	"video":              "视频消息",
+	"file":               "文件消息",
+	"textcard":           "文本卡片消息",
+	"news":               "图文消息",
+	"mpnews":             "图文消息（mpnews）",
+	"markdown":           "markdown消息",
+	"miniprogram_notice": "小程序通知消息",
+	"taskcard":           "任务卡片消息",
+}
+
+func init() {
+	for _, m := range []MessageKey{Text{}, Image{}, Voice{}, Video{}, File{}, TextCard{}, News{}, MpNews{}, Markdown{}, MiniProgram{}, TaskCard{}} {
+		messageTypes[m.key()] = reflect.TypeOf(m)
+		messageTypeKeys = append(messageTypeKeys, m.key())
+	}
+}
+
+// SupportedMessageTypes 返回已支持的全部 msgtype，如 "text"、"image"、"taskcard"
+func SupportedMessageTypes() []string {
+	return append([]string(nil), messageTypeKeys...)
+}
+
+// MessageTypeLabel 返回 msgtype 的中文名称，不支持的类型返回空字符串
+func MessageTypeLabel(msgType string) string {
+	if _, ok := messageTypes[msgType]; !ok {
+		return ""
+	}
+	return messageTypeLabels[msgType]
+}
+
+// Marshal 生成消息本身的请求体（不含 access_token），可用于消息存档及重放。
+// 不包含客户端的默认配置、Environment 标签等处理，需要与 Send 发送内容一致时使用 Notify.Marshal。
+// 字段按名称排序，相同输入的输出字节一致，可用于快照测试
+func Marshal(receiver MessageReceiver, message interface{}, options *MessageOptions, agentID int64) ([]byte, error) {
+	msgBody, err := buildMessageBody(receiver, message, options, agentID)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(msgBody)
+}
+
+// Marshal 生成与 Send 发送内容一致的请求体（不含 access_token），合并默认配置、添加 Environment 标签并使用客户端的 Codec 编码。
+// OnlyActive 过滤及拦截器对请求内容的修改不包含在内
+func (n *Notify) Marshal(receiver MessageReceiver, message interface{}, options *MessageOptions) ([]byte, error) {
+	if err := n.requireAgent(); err != nil {
+		return nil, err
+	}
+	msgBody, err := n.messageBody(receiver, message, n.resolveOptions(context.Background(), message, options))
+	if err != nil {
+		return nil, err
+	}
+	return n.codec.Marshal(msgBody)
+}
+
+// Unmarshal 解析 Marshal 生成的请求体，还原接收者、具体类型的消息、消息配置及 agentID，未设置任何配置时 options 为 nil
+func Unmarshal(data []byte) (receiver MessageReceiver, message interface{}, options *MessageOptions, agentID int64, err error) {
+	var body struct {
+		MessageReceiver
+		AgentID                int64  `json:"agentid"`
+		MsgType                string `json:"msgtype"`
+		Safe                   int    `json:"safe"`
+		EnableIDTrans          int    `json:"enable_id_trans"`
+		EnableDuplicateCheck   int    `json:"enable_duplicate_check"`
+		DuplicateCheckInterval int    `json:"duplicate_check_interval"`
+	}
+	if err = json.Unmarshal(data, &body); err != nil {
+		return receiver, nil, nil, 0, fmt.Errorf("decode message body error: %w", err)
+	}
+
+	t, ok := messageTypes[body.MsgType]
+	if !ok {
+		return receiver, nil, nil, 0, fmt.Errorf("%w: %s", ErrUnsupportedMessageType, body.MsgType)
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return receiver, nil, nil, 0, fmt.Errorf("decode message body error: %w", err)
+	}
+	v := reflect.New(t)
+	if err = json.Unmarshal(fields[body.MsgType], v.Interface()); err != nil {
+		return receiver, nil, nil, 0, fmt.Errorf("decode %s message error: %w", body.MsgType, err)
+	}
+
+	if body.Safe != 0 || body.EnableIDTrans != 0 || body.EnableDuplicateCheck != 0 {
+		options = &MessageOptions{
+			Safe:                   body.Safe == 1,
+			EnableIDTrans:          body.EnableIDTrans == 1,
+			EnableDuplicateCheck:   body.EnableDuplicateCheck == 1,
+			DuplicateCheckInterval: body.DuplicateCheckInterval,
+		}
+	}
+	return body.MessageReceiver, v.Elem().Interface(), options, body.AgentID, nil
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	receiver := MessageReceiver{ToUser: "alice|bob", ToParty: "2"}
+	message := TextCard{Title: "放假通知", Description: "清明节放假通知", URL: "https://work.weixin.qq.com/"}
+	options := &MessageOptions{EnableDuplicateCheck: true, DuplicateCheckInterval: 300}
+
+	b, err := Marshal(receiver, message, options, 1000002)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v, want no error", err)
+	}
+
+	gotReceiver, gotMessage, gotOptions, gotAgentID, err := Unmarshal(b)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v, want no error", err)
+	}
+	if gotReceiver != receiver {
+		t.Errorf("Unmarshal() receiver = %v, want %v", gotReceiver, receiver)
+	}
+	if !reflect.DeepEqual(gotMessage, message) {
+		t.Errorf("Unmarshal() message = %#v, want %#v", gotMessage, message)
+	}
+	if !reflect.DeepEqual(gotOptions, options) {
+		t.Errorf("Unmarshal() options = %v, want %v", gotOptions, options)
+	}
+	if gotAgentID != 1000002 {
+		t.Errorf("Unmarshal() agentID = %v, want %v", gotAgentID, 1000002)
+	}
+
+	if _, _, _, _, err := Unmarshal([]byte(`{"msgtype":"unknown"}`)); err == nil {
+		t.Errorf("Unmarshal() unknown msgtype want error")
+	}
+}
+
+func TestSupportedMessageTypes(t *testing.T) {
+	types := SupportedMessageTypes()
+	if len(types) != len(messageTypes) || types[0] != "text" {
+		t.Errorf("SupportedMessageTypes() = %v", types)
+	}
+	for _, msgType := range types {
+		if MessageTypeLabel(msgType) == "" {
+			t.Errorf("MessageTypeLabel(%q) is empty", msgType)
+		}
+	}
+	if MessageTypeLabel("unknown") != "" {
+		t.Errorf("MessageTypeLabel(unknown) want empty")
+	}
+	types[0] = "changed"
+	if SupportedMessageTypes()[0] != "text" {
+		t.Errorf("SupportedMessageTypes() returned shared slice")
+	}
+}
+
+func TestMarshal_Deterministic(t *testing.T) {
+	receiver := MessageReceiver{ToUser: "alice|bob", ToParty: "2", ToTag: "3"}
+	message := News{Articles: []NewsArticle{{Title: "中秋节礼品领取", Description: "今年中秋节公司有豪礼相送", URL: "https://work.weixin.qq.com/", PicURL: "https://res.mail.qq.com/a.png"}}}
+	options := &MessageOptions{EnableIDTrans: true, EnableDuplicateCheck: true, DuplicateCheckInterval: 300}
+	want := `{"agentid":1,"duplicate_check_interval":300,"enable_duplicate_check":1,"enable_id_trans":1,"msgtype":"news","news":{"articles":[{"title":"中秋节礼品领取","description":"今年中秋节公司有豪礼相送","url":"https://work.weixin.qq.com/","picurl":"https://res.mail.qq.com/a.png"}]},"toparty":"2","totag":"3","touser":"alice|bob"}`
+
+	for i := 0; i < 20; i++ {
+		got, err := Marshal(receiver, message, options, 1)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v, want no error", err)
+		}
+		if string(got) != want {
+			t.Fatalf("Marshal() = %s, want %s", got, want)
+		}
+	}
+
+	var sent []byte
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		sent, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	if _, err := n.Send(receiver, message, options); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if string(bytes.TrimSpace(sent)) != want {
+		t.Errorf("Send() body = %s, want %s", sent, want)
+	}
+}
+
+func TestNotify_Marshal(t *testing.T) {
+	var sent []byte
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		sent, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.Environment = "staging"
+	n.DefaultOptions = &MessageOptions{Safe: true}
+	receiver := MessageReceiver{ToUser: "@all"}
+
+	got, err := n.Marshal(receiver, Text{Content: "x"}, nil)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v, want no error", err)
+	}
+	want := `{"agentid":1,"msgtype":"text","safe":1,"text":{"content":"[STAGING] x"},"toparty":"","totag":"","touser":"@all"}`
+	if string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+	if _, err = n.Send(receiver, Text{Content: "x"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if string(bytes.TrimSpace(sent)) != string(got) {
+		t.Errorf("Send() body = %s, want %s", sent, got)
+	}
+}
@@ -0,0 +1,236 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mediaTTL 临时素材有效期为3天，提前一小时过期以免发送时失效
+const mediaTTL = 3*24*time.Hour - time.Hour
+
+// maxVideoBytes 视频素材的大小限制
+const maxVideoBytes = 10 << 20
+
+// mediaCache 已上传的临时素材缓存，同一文件在有效期内不重复上传
+type mediaCache struct {
+	mu    sync.Mutex
+	items map[string]cachedMedia
+}
+
+type cachedMedia struct {
+	result    UploadMediaResult
+	expiresAt time.Time
+}
+
+// uploadCached 上传文件并返回 media_id，文件路径、文件名、大小及修改时间均未变化时复用有效期内的 media_id
+func (n *Notify) uploadCached(media UploadMedia) (string, error) {
+	result, err := n.uploadCachedResult(media)
+	return result.MediaID, err
+}
+
+// uploadCachedResult 同 uploadCached，返回完整的上传结果，复用时返回首次上传的结果，上传失败的结果不缓存
+func (n *Notify) uploadCachedResult(media UploadMedia) (UploadMediaResult, error) {
+	info, err := os.Stat(media.Path)
+	if err != nil {
+		return UploadMediaResult{}, fmt.Errorf("open media file error: %w", err)
+	}
+	path, err := filepath.Abs(media.Path)
+	if err != nil {
+		return UploadMediaResult{}, fmt.Errorf("open media file error: %w", err)
+	}
+	key := media.Type + ":" + path + ":" + media.Filename + ":" + strconv.FormatInt(info.Size(), 10) + ":" + strconv.FormatInt(info.ModTime().UnixNano(), 10)
+
+	n.media.mu.Lock()
+	cached, ok := n.media.items[key]
+	n.media.mu.Unlock()
+	if ok && n.now().Before(cached.expiresAt) {
+		return cached.result, nil
+	}
+
+	result, err := n.Upload(media)
+	if err != nil || result.ErrorCode != 0 {
+		return result, err
+	}
+
+	n.media.mu.Lock()
+	if n.media.items == nil {
+		n.media.items = make(map[string]cachedMedia)
+	}
+	n.media.items[key] = cachedMedia{result: result, expiresAt: n.now().Add(mediaTTL)}
+	n.media.mu.Unlock()
+	return result, nil
+}
+
+// DeleteMaterial 删除应用的永久素材，临时素材3天后自动失效无需删除
+func (n *Notify) DeleteMaterial(mediaID string) error {
+	if mediaID == "" {
+		return errors.New("media id can not be empty")
+	}
+	if err := n.requireAgent(); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("agentid", strconv.FormatInt(n.agentID, 10))
+	query.Set("media_id", mediaID)
+	return n.callAPI(http.MethodGet, "material/del", query, nil, nil)
+}
+
+// MpNewsDraft 待发送的 mpnews 图文，ThumbPath 为缩略图的本地文件路径，发送时上传并填充 ThumbMediaID
+type MpNewsDraft struct {
+	MpNewsArticle
+	ThumbPath string
+}
+
+// SendMpNewsWithThumbs 上传每条图文的缩略图后发送 mpnews 消息，已上传过的缩略图在有效期内复用
+func (n *Notify) SendMpNewsWithThumbs(receiver MessageReceiver, articles []MpNewsDraft, options *MessageOptions) (MessageResult, error) {
+	if len(articles) == 0 {
+		return MessageResult{}, errors.New("mpnews articles can not be empty")
+	}
+
+	message := MpNews{Articles: make([]MpNewsArticle, 0, len(articles))}
+	for i, draft := range articles {
+		article := draft.MpNewsArticle
+		if draft.ThumbPath != "" {
+			mediaID, err := n.uploadCached(UploadMedia{Type: "image", Path: draft.ThumbPath})
+			if err != nil {
+				return MessageResult{}, fmt.Errorf("upload thumb of article %d error: %w", i, err)
+			}
+			article.ThumbMediaID = mediaID
+		}
+		message.Articles = append(message.Articles, article)
+	}
+	return n.Send(receiver, message, options)
+}
+
+// SendVideoFile 上传本地视频文件并发送视频消息，文件不超过10MB，有效期内重复发送同一文件时复用 media_id
+func (n *Notify) SendVideoFile(receiver MessageReceiver, path, title, description string, options *MessageOptions) (MessageResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return MessageResult{}, fmt.Errorf("open media file error: %w", err)
+	}
+	if info.Size() > maxVideoBytes {
+		return MessageResult{}, fmt.Errorf("video file size %d exceeds limit %d", info.Size(), maxVideoBytes)
+	}
+	mediaID, err := n.uploadCached(UploadMedia{Type: "video", Path: path})
+	if err != nil {
+		return MessageResult{}, err
+	}
+	return n.Send(receiver, Video{MediaID: mediaID, Title: title, Description: description}, options)
+}
+
+// UploadBatch 并发上传多个文件，concurrency 为并发数，默认 1，结果顺序与 medias 一致。同一文件在有效期内复用已上传的结果，
+// 单个文件上传失败不影响其他文件，文件读取、请求失败或返回非 0 错误码时错误记录在对应结果的 Err 中
+func (n *Notify) UploadBatch(medias []UploadMedia, concurrency int) []UploadMediaResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	results := make([]UploadMediaResult, len(medias))
+	if len(medias) == 0 {
+		return results
+	}
+	// 预先获取 token，避免并发上传时各自刷新
+	if _, _, err := n.GetToken(); err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range medias {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result, err := n.uploadCachedResult(medias[i])
+			if err == nil && result.ErrorCode != 0 {
+				err = newAPIError(result.ErrorCode, result.ErrorMsg)
+			}
+			result.Err = err
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// contentHash 计算 f 内容的 SHA-256 并将读取位置恢复到开头
+func contentHash(f *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("read media file error: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("read media file error: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// lookupMediaHash 查询有效期内相同类型及内容的 media_id，设置了 Store 时从 Store 查询
+func (n *Notify) lookupMediaHash(mediaType, hash string) (string, bool) {
+	key := mediaType + ":" + hash
+	if n.store != nil {
+		b, err := n.store.Get(n.storeKey("media:" + key))
+		return string(b), err == nil && len(b) > 0
+	}
+	n.media.mu.Lock()
+	defer n.media.mu.Unlock()
+	cached, ok := n.media.items["hash:"+key]
+	if !ok || !n.now().Before(cached.expiresAt) {
+		return "", false
+	}
+	return cached.result.MediaID, true
+}
+
+// saveMediaHash 记录内容对应的 media_id，有效期与临时素材一致
+func (n *Notify) saveMediaHash(mediaType, hash, mediaID string) {
+	key := mediaType + ":" + hash
+	if n.store != nil {
+		_ = n.store.Set(n.storeKey("media:"+key), []byte(mediaID), mediaTTL)
+		return
+	}
+	n.media.mu.Lock()
+	defer n.media.mu.Unlock()
+	if n.media.items == nil {
+		n.media.items = make(map[string]cachedMedia)
+	}
+	n.media.items["hash:"+key] = cachedMedia{result: UploadMediaResult{MediaID: mediaID}, expiresAt: n.now().Add(mediaTTL)}
+}
+
+// sniffBytes http.DetectContentType 检测内容类型使用的字节数
+const sniffBytes = 512
+
+// detectMIME 读取 r 的前 512 字节检测 MIME 类型，返回的 reader 包含全部内容
+func detectMIME(r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, sniffBytes)
+	k, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", r, fmt.Errorf("read media file error: %w", err)
+	}
+	head = head[:k]
+	return http.DetectContentType(head), io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// checkMediaMIME 校验 image 类型的素材内容为图片。amr 语音及部分 mp4 视频无法通过内容识别，其他类型不做校验
+func checkMediaMIME(mediaType, mime string) error {
+	if mediaType == "image" && !strings.HasPrefix(mime, "image/") {
+		return fmt.Errorf("media type image does not match detected content type %s", mime)
+	}
+	return nil
+}
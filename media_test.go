@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNotify_DeleteMaterial(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/material/del" || q.Get("media_id") != "m1" || q.Get("agentid") != "1" || q.Get("access_token") != "token" {
+			_, _ = w.Write([]byte(`{"errcode":40007,"errmsg":"invalid media_id"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"deleted"}`))
+	})
+
+	if err := n.DeleteMaterial("m1"); err != nil {
+		t.Errorf("DeleteMaterial() error = %v, want no error", err)
+	}
+	if err := n.DeleteMaterial("m2"); err == nil {
+		t.Errorf("DeleteMaterial() want error for invalid media id")
+	}
+}
+
+func TestNotify_SendVideoFile(t *testing.T) {
+	var uploads int32
+	var sent string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/media/upload":
+			atomic.AddInt32(&uploads, 1)
+			if r.URL.Query().Get("type") != "video" {
+				t.Errorf("upload type = %s, want video", r.URL.Query().Get("type"))
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"video","media_id":"v1"}`))
+		case "/message/send":
+			b, _ := io.ReadAll(r.Body)
+			sent = string(b)
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	})
+	path := filepath.Join(t.TempDir(), "a.mp4")
+	if err := os.WriteFile(path, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := n.SendVideoFile(MessageReceiver{ToUser: "@all"}, path, "title", "desc", nil); err != nil {
+			t.Fatalf("SendVideoFile() error = %v, want no error", err)
+		}
+	}
+	if uploads != 1 {
+		t.Errorf("uploads = %d, want 1", uploads)
+	}
+	if !strings.Contains(sent, `"video":{"media_id":"v1","title":"title","description":"desc"}`) {
+		t.Errorf("SendVideoFile() body = %s", sent)
+	}
+
+	if err := os.Truncate(path, maxVideoBytes+1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.SendVideoFile(MessageReceiver{ToUser: "@all"}, path, "title", "desc", nil); err == nil {
+		t.Errorf("SendVideoFile() want error for file over 10MB")
+	}
+}
+
+func TestNotify_UploadBatch(t *testing.T) {
+	var uploads int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&uploads, 1)
+		_, header, err := r.FormFile("media")
+		if err != nil {
+			t.Errorf("upload form error = %v", err)
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"image","media_id":"m-` + header.Filename + `"}`))
+	})
+
+	dir := t.TempDir()
+	var medias []UploadMedia
+	for _, name := range []string{"a.png", "b.png", "c.png"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(pngHeader+name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		medias = append(medias, UploadMedia{Type: "image", Path: path})
+	}
+	medias = append(medias, UploadMedia{Type: "image", Path: filepath.Join(dir, "missing.png")}, medias[0])
+
+	results := n.UploadBatch(medias, 2)
+	for i, want := range []string{"m-a.png", "m-b.png", "m-c.png", "", "m-a.png"} {
+		if results[i].MediaID != want || (want == "") != (results[i].Err != nil) || (want != "" && results[i].Type != "image") {
+			t.Errorf("UploadBatch()[%d] = %+v, want media id %q", i, results[i], want)
+		}
+	}
+	if got := atomic.LoadInt32(&uploads); got < 3 || got > 4 {
+		t.Errorf("UploadBatch() uploads = %d, want 3 or 4", got)
+	}
+}
+
+func TestNotify_UploadDedupByContent(t *testing.T) {
+	var uploads int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		id := atomic.AddInt32(&uploads, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"file","media_id":"m` + strconv.Itoa(int(id)) + `"}`))
+	})
+	n.UploadConfig.DedupByContent = true
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	first, err := n.Upload(UploadMedia{Type: "file", Path: write("a.txt", "report")})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want no error", err)
+	}
+	second, err := n.Upload(UploadMedia{Type: "file", Path: write("b.txt", "report")})
+	if err != nil {
+		t.Fatalf("Upload() error = %v, want no error", err)
+	}
+	if first.MediaID != "m1" || second.MediaID != "m1" || second.ContentHash == "" || second.ContentHash != first.ContentHash {
+		t.Errorf("Upload() = %+v, %+v, want shared media id and hash", first, second)
+	}
+
+	if third, _ := n.Upload(UploadMedia{Type: "file", Path: write("c.txt", "other")}); third.MediaID != "m2" {
+		t.Errorf("Upload() media id = %q, want m2 for different content", third.MediaID)
+	}
+	if got := atomic.LoadInt32(&uploads); got != 2 {
+		t.Errorf("Upload() uploads = %d, want 2", got)
+	}
+}
+
+// pngHeader PNG 文件头，用于构造能通过内容检测的图片
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func TestNotify_UploadDetectedMIME(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"image","media_id":"m1"}`))
+	})
+
+	dir := t.TempDir()
+	image := filepath.Join(dir, "a.png")
+	text := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(image, []byte(pngHeader+"data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(text, []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := n.Upload(UploadMedia{Type: "image", Path: image})
+	if err != nil || result.DetectedMIME != "image/png" {
+		t.Errorf("Upload() = %+v, %v, want detected image/png", result, err)
+	}
+	if _, err = n.Upload(UploadMedia{Type: "image", Path: text}); err == nil {
+		t.Errorf("Upload() text as image want error")
+	}
+	if result, err = n.Upload(UploadMedia{Type: "file", Path: text}); err != nil || !strings.HasPrefix(result.DetectedMIME, "text/plain") {
+		t.Errorf("Upload() = %+v, %v, want detected text/plain", result, err)
+	}
+}
+
+func TestNotify_SendMpNewsWithThumbs(t *testing.T) {
+	var uploads int32
+	var sent []MpNews
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/media/upload" {
+			atomic.AddInt32(&uploads, 1)
+			_, header, err := r.FormFile("media")
+			if err != nil {
+				t.Errorf("upload form error = %v", err)
+				return
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"image","media_id":"thumb-` + header.Filename + `"}`))
+			return
+		}
+		var body struct {
+			MpNews MpNews `json:"mpnews"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		sent = append(sent, body.MpNews)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "b.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(pngHeader+name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	articles := []MpNewsDraft{
+		{MpNewsArticle: MpNewsArticle{Title: "一", Content: "c"}, ThumbPath: filepath.Join(dir, "a.png")},
+		{MpNewsArticle: MpNewsArticle{Title: "二", Content: "c"}, ThumbPath: filepath.Join(dir, "b.png")},
+		{MpNewsArticle: MpNewsArticle{Title: "三", Content: "c"}, ThumbPath: filepath.Join(dir, "a.png")},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := n.SendMpNewsWithThumbs(MessageReceiver{ToUser: "@all"}, articles, nil); err != nil {
+			t.Fatalf("SendMpNewsWithThumbs() error = %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&uploads); got != 2 {
+		t.Errorf("uploads = %d, want each thumb uploaded once", got)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("sends = %d, want 2", len(sent))
+	}
+	for _, news := range sent {
+		var got []string
+		for _, article := range news.Articles {
+			got = append(got, article.ThumbMediaID)
+		}
+		if want := []string{"thumb-a.png", "thumb-b.png", "thumb-a.png"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("sent thumb_media_id = %v, want %v", got, want)
+		}
+	}
+}
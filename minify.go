@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxMpNewsContentBytes mpnews 图文内容的字节长度限制
+const maxMpNewsContentBytes = 666 * 1024
+
+var (
+	htmlCommentPattern    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlPrePattern        = regexp.MustCompile(`(?is)<pre[\s>].*?</pre>`)
+	htmlBetweenTagPattern = regexp.MustCompile(`>\s+<`)
+	htmlTrailingPattern   = regexp.MustCompile(`>\s+$`)
+	htmlLeadingPattern    = regexp.MustCompile(`^\s+<`)
+	htmlSpacePattern      = regexp.MustCompile(`\s+`)
+)
+
+// htmlBlockTags 块级标签，与其相邻的标签间空白不影响显示，可以删除；行内标签之间的空白会显示为空格，只合并不删除
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "br": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "head": true, "header": true,
+	"hr": true, "html": true, "li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "style": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "tr": true, "ul": true,
+}
+
+// MinifyContent 压缩 mpnews 图文内容的 HTML，删除注释、合并连续空白并删除块级标签两侧的空白，<pre> 中的内容保持不变。
+// 行内标签之间的空白（如 <b>a</b> <i>b</i>）合并为一个空格。
+// 返回压缩后的内容及其是否在 666K 字节的限制内
+func MinifyContent(html string) (string, bool) {
+	html = htmlCommentPattern.ReplaceAllString(html, "")
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range htmlPrePattern.FindAllStringIndex(html, -1) {
+		b.WriteString(minifySpaces(html[last:loc[0]]))
+		b.WriteString(html[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(minifySpaces(html[last:]))
+
+	minified := strings.TrimSpace(b.String())
+	return minified, len(minified) <= maxMpNewsContentBytes
+}
+
+// minifySpaces 删除块级标签两侧的空白，行内标签之间的空白及其他连续空白合并为一个空格，
+// s 两端与 <pre> 相邻的空白同样删除
+func minifySpaces(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range htmlBetweenTagPattern.FindAllStringIndex(s, -1) {
+		b.WriteString(s[last:loc[0]])
+		prev := s[strings.LastIndexByte(s[:loc[0]], '<')+1 : loc[0]]
+		next := s[loc[1]:]
+		if i := strings.IndexByte(next, '>'); i >= 0 {
+			next = next[:i]
+		}
+		if htmlBlockTags[htmlTagName(prev)] || htmlBlockTags[htmlTagName(next)] {
+			b.WriteString("><")
+		} else {
+			b.WriteString("> <")
+		}
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	s = b.String()
+	s = htmlTrailingPattern.ReplaceAllString(s, ">")
+	s = htmlLeadingPattern.ReplaceAllString(s, "<")
+	return htmlSpacePattern.ReplaceAllString(s, " ")
+}
+
+// htmlTagName 返回标签内容（不含尖括号）的小写标签名，如 "/P" 返回 "p"
+func htmlTagName(tag string) string {
+	tag = strings.TrimPrefix(tag, "/")
+	if i := strings.IndexAny(tag, " \t\r\n/"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.ToLower(tag)
+}
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMinifyContent(t *testing.T) {
+	html := `
+<div>
+    <!-- header -->
+    <p>hello   world</p>
+    <pre>  keep
+    this  </pre>
+</div>
+`
+	got, fits := MinifyContent(html)
+	want := "<div><p>hello world</p><pre>  keep\n    this  </pre></div>"
+	if got != want || !fits {
+		t.Errorf("MinifyContent() = %q, %v, want %q, true", got, fits, want)
+	}
+
+	// 行内标签之间的空白显示为空格，合并而不删除
+	inline := "<p>\n  <b>a</b>   <i>b</i>\n</p>\n<div> <span>c</span></div>"
+	if got, _ = MinifyContent(inline); got != "<p><b>a</b> <i>b</i></p><div><span>c</span></div>" {
+		t.Errorf("MinifyContent() = %q, want space kept between inline tags", got)
+	}
+
+	if _, fits = MinifyContent(strings.Repeat("a", maxMpNewsContentBytes+1)); fits {
+		t.Errorf("MinifyContent() fits = true, want false for content over limit")
+	}
+}
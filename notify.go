@@ -7,16 +7,21 @@ package notify
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
-	"reflect"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 const (
@@ -24,8 +29,18 @@ const (
 )
 
 type UploadMedia struct {
-	Type string
-	Path string
+	Type     string
+	Path     string
+	Filename string // 上传的文件名，接收人看到的文件名，默认为 Path 的文件名
+}
+
+// UploadConfig 上传临时素材的超时及大小限制
+type UploadConfig struct {
+	Timeout time.Duration // 上传请求超时时间，默认10秒
+	MaxSize int64         // 文件大小上限（字节），超过时不发起上传，0 表示不限制
+	// DedupByContent 按文件内容去重，Upload 上传前计算内容的 SHA-256，有效期内上传过相同内容时直接返回已有的 media_id。
+	// 设置了 Store 时记录保存在 Store 中，可在多个进程间共享
+	DedupByContent bool
 }
 
 type UploadMediaResult struct {
@@ -34,9 +49,13 @@ type UploadMediaResult struct {
 	Type      string `json:"type"`
 	MediaID   string `json:"media_id"`
 	CreatedAt string `json:"created_at"`
+
+	ContentHash  string `json:"-"` // 非接口返回。开启 UploadConfig.DedupByContent 时为文件内容的 SHA-256，十六进制编码
+	DetectedMIME string `json:"-"` // 非接口返回。根据文件内容检测的 MIME 类型，如 image/png
+	Err          error  `json:"-"` // 非接口返回。UploadBatch 中该文件的上传错误
 }
 
-// MessageReceiver 消息接收者 ToUser、ToParty、ToTag 至少一个
+// MessageReceiver 消息接收者 ToUser、ToParty、ToTag 至少一个，发送前会去除 id 两端的空白及空项，见 Normalize
 type MessageReceiver struct {
 	ToUser  string `json:"touser"`  // 成员ID列表（消息接收者，多个接收者用‘|’分隔，最多支持1000个）。特殊情况：指定为@all，则向关注该企业应用的全部成员发送
 	ToParty string `json:"toparty"` // 指定接收消息的部门，部门ID列表，多个接收者用‘|’分隔，最多支持100个。当touser为”@all”时忽略本参数
@@ -46,9 +65,20 @@ type MessageReceiver struct {
 // MessageOptions 消息配置包括加密、id转译、重复检查等。部分消息类型只支持部分配置详见官方文档
 type MessageOptions struct {
 	Safe                   bool `json:"safe"`                     // 表示是否是保密消息，默认否
-	EnableIDTrans          bool `json:"enable_id_trans"`          // 表示是否开启id转译，默认否
+	EnableIDTrans          bool `json:"enable_id_trans"`          // 表示是否开启id转译，默认否。仅部分消息类型支持，见 IDTransFields，本次发送对不支持的类型开启时返回 ErrIDTransUnsupported，DefaultOptions 等默认配置中开启时对不支持的类型不生效
 	EnableDuplicateCheck   bool `json:"enable_duplicate_check"`   // 表示是否开启重复消息检查，默认否
 	DuplicateCheckInterval int  `json:"duplicate_check_interval"` // 表示是否重复消息检查的时间间隔，默认1800s，最大不超过4小时
+
+	FailOnAllInvalid           bool          `json:"-"` // 非接口参数。发送成功但全部接收人均无效时返回 ErrAllReceiversInvalid，默认否
+	Priority                   Priority      `json:"-"` // 非接口参数。消息优先级，紧急消息不受 SendPolicy 免打扰时段限制
+	TTL                        time.Duration `json:"-"` // 非接口参数。包括重试在内的整个发送过程的时限，超时后不再重试并返回 context.DeadlineExceeded
+	OnlyActive                 bool          `json:"-"` // 非接口参数。发送前将 ToUser 过滤为已激活的成员，尽力而为，查询失败时仍发送给全部接收人
+	Bypass                     bool          `json:"-"` // 非接口参数。跳过客户端限流及熔断直接发送，用于紧急告警，不占用限流令牌，发送结果仍计入熔断统计
+	FallbackWebhook            bool          `json:"-"` // 非接口参数。发送失败时将文本摘要发送到 SetFallbackWebhook 设置的群机器人，成功时返回 FallbackError
+	ForceDisableDuplicateCheck bool          `json:"-"` // 非接口参数。关闭本次发送的重复消息检查，覆盖 DefaultOptions 等默认配置中开启的检查，用于重发相同内容
+	AutoChunk                  bool          `json:"-"` // 非接口参数。接收人超过单次发送上限时拆分为多次发送并返回合并后的结果，见 SendChunked
+	DedupKey                   string        `json:"-"` // 非接口参数。去重键，与 DedupWindow 同时设置时，时间窗口内相同去重键的消息只发送一次，见 ErrDuplicateSuppressed
+	DedupWindow                time.Duration `json:"-"` // 非接口参数。去重时间窗口
 }
 
 // MessageResult 消息发送结果。如果部分接收人无权限或不存在，发送仍然执行，但会返回无效的部分（即invaliduser或invalidparty或invalidtag），常见的原因是接收人不在应用的可见范围内。
@@ -60,6 +90,116 @@ type MessageResult struct {
 	InvalidUser  string `json:"invaliduser"`
 	InvalidParty string `json:"invalidparty"`
 	InvalidTag   string `json:"invalidtag"`
+	MsgID        string `json:"msgid"` // 消息id，企业微信不提供应用消息的已读状态查询，msgid 仅可用于撤回消息
+}
+
+// ErrAllReceiversInvalid 发送成功但全部接收人均无效，消息实际未送达任何人
+var ErrAllReceiversInvalid = errors.New("message sent but all receivers are invalid")
+
+// SendStatus 消息发送结果分类
+type SendStatus int
+
+const (
+	StatusOK      SendStatus = iota // 全部接收人发送成功
+	StatusPartial                   // 发送成功，但部分接收人无效
+	StatusFailed                    // 发送失败
+)
+
+func (s SendStatus) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusPartial:
+		return "partial"
+	default:
+		return "failed"
+	}
+}
+
+// Status 根据错误码及无效接收人对发送结果进行分类
+func (r MessageResult) Status() SendStatus {
+	if r.ErrorCode != 0 {
+		return StatusFailed
+	}
+	if r.IsPartial() {
+		return StatusPartial
+	}
+	return StatusOK
+}
+
+// IsPartial 发送成功但存在无效的接收人（invaliduser、invalidparty 或 invalidtag）
+func (r MessageResult) IsPartial() bool {
+	return r.ErrorCode == 0 && r.HasInvalid()
+}
+
+// HasInvalid 是否存在无效的接收人，不论发送是否成功
+func (r MessageResult) HasInvalid() bool {
+	return r.InvalidUser != "" || r.InvalidParty != "" || r.InvalidTag != ""
+}
+
+// InvalidUsers 返回无效的成员id列表
+func (r MessageResult) InvalidUsers() []string {
+	return splitInvalid(r.InvalidUser)
+}
+
+// InvalidParties 返回无效的部门id列表
+func (r MessageResult) InvalidParties() []string {
+	return splitInvalid(r.InvalidParty)
+}
+
+// InvalidTags 返回无效的标签id列表
+func (r MessageResult) InvalidTags() []string {
+	return splitInvalid(r.InvalidTag)
+}
+
+// InvalidReceivers 按接收人类型 "user"、"party"、"tag" 返回无效的接收人，不包含没有无效接收人的类型
+func (r MessageResult) InvalidReceivers() map[string][]string {
+	invalid := make(map[string][]string, 3)
+	if ids := r.InvalidUsers(); len(ids) > 0 {
+		invalid["user"] = ids
+	}
+	if ids := r.InvalidParties(); len(ids) > 0 {
+		invalid["party"] = ids
+	}
+	if ids := r.InvalidTags(); len(ids) > 0 {
+		invalid["tag"] = ids
+	}
+	return invalid
+}
+
+// splitInvalid 拆分‘|’分隔的无效接收人列表
+func splitInvalid(ids string) []string {
+	if ids == "" {
+		return nil
+	}
+	return strings.Split(ids, "|")
+}
+
+// allReceiversInvalid 检查 receiver 中的成员、部门、标签是否全部出现在发送结果的无效列表中
+func allReceiversInvalid(receiver MessageReceiver, result MessageResult) bool {
+	if result.ErrorCode != 0 || receiver.ToUser == "@all" {
+		return false
+	}
+	return containsAll(result.InvalidUser, receiver.ToUser, true) &&
+		containsAll(result.InvalidParty, receiver.ToParty, false) &&
+		containsAll(result.InvalidTag, receiver.ToTag, false)
+}
+
+// containsAll 检查 '|' 分隔的 ids 是否全部包含在 invalid 中，返回包中的 userid 统一为小写
+func containsAll(invalid, ids string, ignoreCase bool) bool {
+	if ignoreCase {
+		invalid, ids = strings.ToLower(invalid), strings.ToLower(ids)
+	}
+	set := make(map[string]bool)
+	for _, id := range strings.Split(invalid, "|") {
+		set[id] = true
+	}
+	for _, id := range strings.Split(ids, "|") {
+		if id != "" && !set[id] {
+			return false
+		}
+	}
+	return true
 }
 
 type MessageKey interface {
@@ -164,7 +304,7 @@ type MpNewsArticle struct {
 
 // Markdown Markdown消息
 type Markdown struct {
-	Content string `json:"content"` // markdown内容，最长不超过2048个字节，必须是utf8编码
+	Content string `json:"content"` // markdown内容，最长不超过2048个字节，必须是utf8编码，发送时去除开头的 BOM，非 utf8 编码返回 ErrInvalidUTF8
 }
 
 func (t Markdown) key() string {
@@ -226,6 +366,52 @@ type Notify struct {
 	Token          string
 	TokenExpiresAt int64
 	CacheFilePath  string // 新增缓存文件路径配置
+	UploadConfig   UploadConfig
+	DefaultOptions *MessageOptions // 默认消息配置，与每次发送的配置合并
+	StalenessGrace time.Duration   // 共享存储 Store 读取失败时，内存中的 token 过期后仍可继续使用的宽限时长，默认为 0
+	Environment    string          // 环境标签，非空时在文本类消息内容前添加如 [STAGING] 的前缀，避免误将测试消息当作生产告警
+
+	// TokenExpiryMargin 按 expires_in 计算过期时间时预留的时长，避免本机时钟偏差导致使用已过期的 token，默认 60 秒，小于 0 表示不预留
+	TokenExpiryMargin time.Duration
+	// CacheLockTimeout 读写缓存文件前等待其他进程释放文件锁的时长，默认 1 秒，读取超时时重新获取 token，写入超时时不写入缓存文件，不影响发送
+	CacheLockTimeout time.Duration
+	// MaxBodySize 编码后的消息请求体的最大字节数，超过时不发送并返回 ErrBodyTooLarge，默认 8MB，小于 0 表示不限制
+	MaxBodySize int64
+
+	mu      sync.Mutex         // 保护 Token 及 TokenExpiresAt
+	refresh singleflight.Group // 合并当前客户端并发的 token 刷新请求，各客户端的接口地址、Store 及 secret 可能不同，不在客户端间共享
+	baseURL string             // 接口地址前缀，默认为 apiPrefix
+	now     func() time.Time   // 当前时间，测试中可替换以模拟 token 过期
+
+	tokenRetries int             // token 过期重试次数
+	tags         tagCache        // 标签名缓存
+	users        userIDCache     // 邮箱、手机号到 userid 的缓存
+	active       activeCache     // 成员激活状态缓存，见 ActiveUsers
+	aliases      receiverAliases // 接收者别名
+	media        mediaCache      // 已上传的临时素材缓存
+	sends        sendCounter     // 当天发送成功数
+	breaker      circuitBreaker
+	limiter      rateLimiter // 客户端限流，见 SetRateLimit
+	outage       outageQueue // 熔断期间的故障队列，见 EnableOutageQueue
+	test         testMode    // 测试模式，见 EnableTestMode
+
+	policy       SendPolicy
+	interceptors []SendInterceptor
+	onSend       func(info SendInfo)
+	codec        Codec
+	httpClient   *http.Client
+	store        Store
+
+	secretProvider func() (string, error)
+	testUser       string // SendToSelf 的接收成员
+	fallbackKey    string // 降级发送的群机器人 webhook key
+	tracer         Tracer
+	typeOptions    map[string]*MessageOptions // 按消息类型的默认配置
+
+	unsupportedTypeFallback bool                            // markdown 不受支持时以文本消息重发
+	onPartialFailure        func(result MessageResult) bool // 部分接收人无效时的回调，见 OnPartialFailure
+	heartbeat               heartbeat                       // 发送链路心跳，见 StartHeartbeat
+	dedup                   dedupCache                      // 未设置 Store 时的去重记录，见 MessageOptions.DedupKey
 }
 
 type GetTokenResult struct {
@@ -235,43 +421,250 @@ type GetTokenResult struct {
 	ExpiresIn int64  `json:"expires_in,omitempty"`
 }
 
-// New client，corpID 企业ID，在企业信息页面查看, agentID + appSecret 在应用页面查看
+// New client，corpID 企业ID，在企业信息页面查看, agentID + appSecret 在应用页面查看。
+// 仅调用通讯录等企业级接口时 agentID 可为 0，此时 appSecret 为对应的 secret，发送消息等应用接口返回 ErrNoAgentID
 func New(corpID string, agentID int64, appSecret string) *Notify {
 	n := &Notify{
 		corpID: corpID, agentID: agentID, appSecret: appSecret,
 		CacheFilePath: ".notify", // 默认缓存文件路径
+		baseURL:       apiPrefix,
+		tokenRetries:  1,
+		codec:         stdCodec{},
+		now:           time.Now,
 	}
-	_ = n.loadTokenCache()
+	_ = n.loadTokenCache(context.Background())
 	return n
 }
 
+// ErrNoAgentID 客户端未设置 agentID，无法调用发送消息等应用接口
+var ErrNoAgentID = errors.New("agentid not set, client can only call corp level api")
+
+// requireAgent 校验应用接口所需的 agentID，agentid 仅由需要它的接口显式添加，callAPI 不会自动添加
+func (n *Notify) requireAgent() error {
+	if n.agentID == 0 {
+		return ErrNoAgentID
+	}
+	return nil
+}
+
 // Send message with options to receiver, options can be nil
 func (n *Notify) Send(receiver MessageReceiver, message interface{}, options *MessageOptions) (MessageResult, error) {
-	var result MessageResult
+	return n.SendContext(context.Background(), receiver, message, options)
+}
+
+// SendContext 同 Send，ctx 取消或超时时停止发送及重试
+func (n *Notify) SendContext(ctx context.Context, receiver MessageReceiver, message interface{}, options *MessageOptions) (MessageResult, error) {
+	if err := n.requireAgent(); err != nil {
+		return MessageResult{}, err
+	}
+	options = n.resolveOptions(ctx, message, options)
+	if options != nil && options.DedupKey != "" && options.DedupWindow > 0 {
+		return n.sendDedup(ctx, receiver, message, options)
+	}
+	return n.sendResolved(ctx, receiver, message, options)
+}
+
+// resolveOptions 合并客户端默认配置、消息类型默认配置、本次发送的配置及 ctx 中的单次请求配置
+func (n *Notify) resolveOptions(ctx context.Context, message interface{}, options *MessageOptions) *MessageOptions {
+	return dropDefaultIDTrans(message, options, applyRequestOptions(ctx, mergeOptions(n.defaultOptions(message), options)))
+}
+
+// sendResolved 使用已合并的配置发送消息，不再合并默认配置及去重，用于拆分、重发等内部的再次发送
+func (n *Notify) sendResolved(ctx context.Context, receiver MessageReceiver, message interface{}, options *MessageOptions) (MessageResult, error) {
+	receiver, err := normalizeReceiver(receiver)
+	if err != nil {
+		return MessageResult{}, err
+	}
+	if options != nil && options.AutoChunk && len(chunkReceiver(receiver)) > 1 {
+		result, _, err := n.sendChunked(ctx, receiver, message, options)
+		return result, err
+	}
+	if options != nil && options.OnlyActive {
+		if receiver, err = n.filterActive(receiver); err != nil {
+			return MessageResult{}, err
+		}
+	}
+	msgBody, err := n.messageBody(receiver, message, options)
+	if err != nil {
+		return MessageResult{}, err
+	}
+	if err = n.applyPolicy(receiver, message, options); err != nil {
+		return MessageResult{}, err
+	}
+
+	if options != nil && options.TTL > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.TTL)
+		defer cancel()
+	}
+
+	if options == nil || !options.Bypass {
+		if err = n.breakerAllow(); err != nil {
+			if n.enqueueOutage(receiver, message, options) {
+				return MessageResult{}, ErrSendQueued
+			}
+			return n.fallback(message, options, MessageResult{}, err)
+		}
+		if err = n.rateLimitWait(ctx); err != nil {
+			return MessageResult{}, err
+		}
+	}
+
+	info := &SendInfo{MsgType: msgBody["msgtype"].(string), CorrelationID: CorrelationID(ctx)}
+	ctx = context.WithValue(ctx, sendInfoKey{}, info)
+	ctx, span := n.startSpan(ctx, "notify.send")
+	span.SetAttribute("notify.msgtype", info.MsgType)
+	span.SetAttribute("notify.users", countIDs(receiver.ToUser))
+	span.SetAttribute("notify.parties", countIDs(receiver.ToParty))
+	span.SetAttribute("notify.tags", countIDs(receiver.ToTag))
+	start := time.Now()
+	result, err := n.sendChain()(ctx, msgBody)
+	info.Timing.Total = time.Since(start)
+	span.SetAttribute("notify.errcode", result.ErrorCode)
+	span.End(err)
+	n.breakerRecord(result, err)
+	if err == nil && result.ErrorCode == 0 {
+		n.flushOutageAsync()
+	}
+	if err != nil && options != nil && options.TTL > 0 && errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("send not completed within ttl %s: %w", options.TTL, err)
+	}
+	if err == nil && options != nil && options.FailOnAllInvalid && allReceiversInvalid(receiver, result) {
+		err = ErrAllReceiversInvalid
+	}
+	if err == nil && result.ErrorCode == 0 && result.HasInvalid() {
+		result = n.retryPartial(ctx, result, message, options)
+	}
+	n.notifySend(info, result, err)
+	if md, ok := message.(Markdown); ok && err == nil && result.ErrorCode == errCodeInvalidMsgType && n.unsupportedTypeFallback {
+		return n.sendResolved(ctx, receiver, MarkdownToText(md), options)
+	}
+	return n.fallback(message, options, result, err)
+}
+
+// messageBody 构造发送的请求内容，在 buildMessageBody 的基础上添加客户端的环境标签
+func (n *Notify) messageBody(receiver MessageReceiver, message interface{}, options *MessageOptions) (map[string]interface{}, error) {
+	// 先处理内容编码再添加环境标签，否则内容开头的 BOM 不再位于开头
+	message, err := sanitizeMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	return buildMessageBody(receiver, labelMessage(n.Environment, message), options, n.agentID)
+}
+
+// buildMessageBody 构造 message/send 接口的请求内容（不含 access_token）
+func buildMessageBody(receiver MessageReceiver, message interface{}, options *MessageOptions, agentID int64) (map[string]interface{}, error) {
 	if message == nil {
-		return result, errors.New("message can not be nil")
+		return nil, ErrNilMessage
+	}
+
+	receiver, err := normalizeReceiver(receiver)
+	if err != nil {
+		return nil, err
 	}
 
 	msgBody := make(map[string]interface{})
 
 	if len(receiver.ToUser) == 0 && len(receiver.ToParty) == 0 && len(receiver.ToTag) == 0 {
-		return result, errors.New("message receiver not set, set at least one")
+		return nil, ErrNoReceiver
 	}
 
 	msgBody["touser"] = receiver.ToUser
 	msgBody["toparty"] = receiver.ToParty
 	msgBody["totag"] = receiver.ToTag
-	msgBody["agentid"] = n.agentID
+	msgBody["agentid"] = agentID
 	setOptions(msgBody, options)
 
 	k, ok := message.(MessageKey)
 	if !ok {
-		return result, fmt.Errorf("unrecognized message type: %T", reflect.TypeOf(message))
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedMessageType, message)
+	}
+	if _, ok := message.(Menu); ok {
+		return nil, fmt.Errorf("%w: %s is only supported by SendKF", ErrUnsupportedMessageType, k.key())
+	}
+	if err := validateIDTrans(k, options); err != nil {
+		return nil, err
+	}
+	if r, ok := message.(RawMessage); ok {
+		if err := r.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if t, ok := message.(TaskCard); ok {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if message, err = sanitizeMessage(message); err != nil {
+		return nil, err
 	}
 	msgBody["msgtype"] = k.key()
 	msgBody[k.key()] = message
+	return msgBody, nil
+}
+
+// SetTypeOptions 设置消息类型 msgType（如 "text"、"taskcard"）的默认配置，合并在 DefaultOptions 之上、每次发送的配置之下。
+// options 为 nil 时删除该类型的默认配置，应在发送前设置
+func (n *Notify) SetTypeOptions(msgType string, options *MessageOptions) {
+	if options == nil {
+		delete(n.typeOptions, msgType)
+		return
+	}
+	if n.typeOptions == nil {
+		n.typeOptions = make(map[string]*MessageOptions)
+	}
+	n.typeOptions[msgType] = options
+}
 
-	return n.sendInternal(msgBody)
+// defaultOptions 返回 message 对应类型的默认配置
+func (n *Notify) defaultOptions(message interface{}) *MessageOptions {
+	if k, ok := message.(MessageKey); ok {
+		if options, ok := n.typeOptions[k.key()]; ok {
+			return mergeOptions(n.DefaultOptions, options)
+		}
+	}
+	return n.DefaultOptions
+}
+
+// mergeOptions 合并默认配置及本次发送的配置，options 中开启的选项及非零值优先。
+// 默认配置中开启的选项无法通过 options 关闭，重复消息检查可通过 ForceDisableDuplicateCheck 关闭
+func mergeOptions(defaults, options *MessageOptions) *MessageOptions {
+	if defaults == nil {
+		return options
+	}
+	merged := *defaults
+	if options == nil {
+		return &merged
+	}
+	merged.Safe = merged.Safe || options.Safe
+	merged.EnableIDTrans = merged.EnableIDTrans || options.EnableIDTrans
+	merged.EnableDuplicateCheck = merged.EnableDuplicateCheck || options.EnableDuplicateCheck
+	if options.DuplicateCheckInterval != 0 {
+		merged.DuplicateCheckInterval = options.DuplicateCheckInterval
+	}
+	merged.FailOnAllInvalid = merged.FailOnAllInvalid || options.FailOnAllInvalid
+	if options.Priority != PriorityNormal {
+		merged.Priority = options.Priority
+	}
+	if options.TTL != 0 {
+		merged.TTL = options.TTL
+	}
+	merged.OnlyActive = merged.OnlyActive || options.OnlyActive
+	merged.Bypass = merged.Bypass || options.Bypass
+	merged.FallbackWebhook = merged.FallbackWebhook || options.FallbackWebhook
+	merged.AutoChunk = merged.AutoChunk || options.AutoChunk
+	if options.DedupKey != "" {
+		merged.DedupKey = options.DedupKey
+	}
+	if options.DedupWindow != 0 {
+		merged.DedupWindow = options.DedupWindow
+	}
+	if options.ForceDisableDuplicateCheck {
+		merged.ForceDisableDuplicateCheck = true
+		merged.EnableDuplicateCheck = false
+		merged.DuplicateCheckInterval = 0
+	}
+	return &merged
 }
 
 // setOptions for message
@@ -283,7 +676,7 @@ func setOptions(msgBody map[string]interface{}, options *MessageOptions) {
 		if options.EnableIDTrans {
 			msgBody["enable_id_trans"] = 1
 		}
-		if options.EnableDuplicateCheck {
+		if options.EnableDuplicateCheck && !options.ForceDisableDuplicateCheck {
 			msgBody["enable_duplicate_check"] = 1
 			if options.DuplicateCheckInterval != 0 {
 				msgBody["duplicate_check_interval"] = options.DuplicateCheckInterval
@@ -292,49 +685,220 @@ func setOptions(msgBody map[string]interface{}, options *MessageOptions) {
 	}
 }
 
-// Upload temp media to server
+// Upload temp media to server，接口返回非 0 错误码时返回 *APIError，err 为 nil 时 MediaID 有效
 func (n *Notify) Upload(media UploadMedia) (UploadMediaResult, error) {
+	return n.upload("", media)
+}
+
+// upload 上传文件，token 为空时使用自动获取的 access_token
+func (n *Notify) upload(token string, media UploadMedia) (UploadMediaResult, error) {
 	var result UploadMediaResult
-	var client = &http.Client{Timeout: 10 * time.Second}
 
 	// read media file
 	f, err := os.Open(media.Path)
 	if err != nil {
 		return result, fmt.Errorf("open media file error: %w", err)
 	}
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
-	fw, err := w.CreateFormFile("media", filepath.Base(media.Path))
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
 	if err != nil {
-		return result, fmt.Errorf("create multipart file error: %w", err)
+		return result, fmt.Errorf("stat media file error: %w", err)
+	}
+	filename := media.Filename
+	if filename == "" {
+		filename = filepath.Base(media.Path)
+	}
+	if !n.UploadConfig.DedupByContent {
+		return n.uploadReaderSize(token, media.Type, filename, f, info.Size())
 	}
-	_, err = io.Copy(fw, f)
+
+	hash, err := contentHash(f)
 	if err != nil {
-		return result, fmt.Errorf("read media file error: %w", err)
+		return result, err
 	}
-	_ = w.Close()
-	// get token
-	token, _, err := n.GetToken()
+	if mediaID, ok := n.lookupMediaHash(media.Type, hash); ok {
+		return UploadMediaResult{ErrorMsg: "ok", Type: media.Type, MediaID: mediaID, ContentHash: hash}, nil
+	}
+	if result, err = n.uploadReaderSize(token, media.Type, filename, f, info.Size()); err != nil {
+		return result, err
+	}
+	result.ContentHash = hash
+	n.saveMediaHash(media.Type, hash, result.MediaID)
+	return result, nil
+}
+
+// UploadReader 上传 r 中的内容作为临时素材，filename 为上传的文件名。
+// 设置了 UploadConfig.MaxSize 时，读取内容超过上限即返回错误
+func (n *Notify) UploadReader(mediaType, filename string, r io.Reader) (UploadMediaResult, error) {
+	return n.UploadReaderSize(mediaType, filename, r, -1)
+}
+
+// UploadReaderSize 同 UploadReader，size 为 r 中内容的字节数，大于等于 0 时请求会携带 Content-Length，
+// 适用于拒绝分块传输的代理；小于 0 表示大小未知，以分块方式上传
+func (n *Notify) UploadReaderSize(mediaType, filename string, r io.Reader, size int64) (UploadMediaResult, error) {
+	return n.uploadReaderSize("", mediaType, filename, r, size)
+}
+
+// uploadReaderSize 上传 r 中的内容，token 为空时使用自动获取的 access_token
+func (n *Notify) uploadReaderSize(token, mediaType, filename string, r io.Reader, size int64) (UploadMediaResult, error) {
+	var result UploadMediaResult
+	if n.UploadConfig.MaxSize > 0 && size > n.UploadConfig.MaxSize {
+		return result, fmt.Errorf("media file size %d exceeds limit %d", size, n.UploadConfig.MaxSize)
+	}
+	mime, r, err := detectMIME(r)
 	if err != nil {
 		return result, err
 	}
-	fmt.Println(token)
+	if err = checkMediaMIME(mediaType, mime); err != nil {
+		return result, err
+	}
+	if n.testModeEnabled() {
+		result, err = n.recordUpload(mediaType, filename, r)
+		result.DetectedMIME = mime
+		return result, err
+	}
+	client := n.client()
+	if n.UploadConfig.Timeout > 0 {
+		c := *client
+		c.Timeout = n.UploadConfig.Timeout
+		client = &c
+	}
+
+	// get token
+	if token == "" {
+		if token, _, err = n.GetToken(); err != nil {
+			return result, err
+		}
+	}
+
+	var body io.Reader
+	var contentType string
+	var contentLength int64 = -1
+	if size >= 0 {
+		// 预先生成表单头尾，Content-Length 为头尾长度加文件大小
+		var head bytes.Buffer
+		w := multipart.NewWriter(&head)
+		if _, err = w.CreateFormFile("media", filename); err != nil {
+			return result, fmt.Errorf("create multipart file error: %w", err)
+		}
+		headLen := head.Len()
+		_ = w.Close()
+		tail := head.Bytes()[headLen:]
+		body = io.MultiReader(bytes.NewReader(head.Bytes()[:headLen]), io.LimitReader(r, size), bytes.NewReader(tail))
+		contentType = w.FormDataContentType()
+		contentLength = int64(head.Len()) + size
+	} else {
+		// 边读取边上传，内存占用与文件大小无关
+		pr, pw := io.Pipe()
+		defer func() { _ = pr.Close() }()
+		w := multipart.NewWriter(pw)
+		go func() {
+			_ = pw.CloseWithError(writeMultipartMedia(w, filename, r, n.UploadConfig.MaxSize))
+		}()
+		body = pr
+		contentType = w.FormDataContentType()
+	}
+
 	// send request
-	res, err := client.Post(fmt.Sprintf("%s/media/upload?access_token=%s&type=%s", apiPrefix, n.Token, media.Type), w.FormDataContentType(), &b)
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/media/upload?access_token=%s&type=%s", n.baseURL, token, mediaType), body)
+	if err != nil {
+		return result, fmt.Errorf("upload media file error: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = contentLength
+	res, err := n.do(client, req)
 	if err != nil {
 		return result, fmt.Errorf("upload media file error: %w", err)
 	}
 	defer func() { _ = res.Body.Close() }()
 
-	err = json.NewDecoder(res.Body).Decode(&result)
+	err = n.decode(res.Body, &result)
 	if err != nil {
 		return result, fmt.Errorf("upload media result decode error: %w", err)
 	}
+	if result.ErrorCode != 0 {
+		return result, fmt.Errorf("upload media file error: %w", newAPIError(result.ErrorCode, result.ErrorMsg))
+	}
+	result.DetectedMIME = mime
 	return result, nil
 }
 
+// writeMultipartMedia 将 r 的内容写入 multipart 表单的 media 字段，limit 大于 0 时读取内容超过 limit 返回错误
+func writeMultipartMedia(w *multipart.Writer, filename string, r io.Reader, limit int64) error {
+	fw, err := w.CreateFormFile("media", filename)
+	if err != nil {
+		return fmt.Errorf("create multipart file error: %w", err)
+	}
+	if limit > 0 {
+		written, err := io.Copy(fw, io.LimitReader(r, limit+1))
+		if err != nil {
+			return fmt.Errorf("read media file error: %w", err)
+		}
+		if written > limit {
+			return fmt.Errorf("media file size exceeds limit %d", limit)
+		}
+	} else if _, err = io.Copy(fw, r); err != nil {
+		return fmt.Errorf("read media file error: %w", err)
+	}
+	return w.Close()
+}
+
+// EnableTokenPersist 开启 access_token 持久化并读取缓存文件中未过期的 token，需先通过 SetCacheFilePath 设置路径。
+// 缓存文件路径为空时不开启，需要得知失败原因时使用 EnableTokenPersistE
 func (n *Notify) EnableTokenPersist() {
+	_ = n.EnableTokenPersistE()
+}
+
+// EnableTokenPersistE 同 EnableTokenPersist，缓存文件路径为空时返回错误且不开启
+func (n *Notify) EnableTokenPersistE() error {
+	if n.CacheFilePath == "" {
+		return errors.New("token persist requires cache file path, call SetCacheFilePath first")
+	}
 	n.TokenPersist = true
+	_ = n.loadTokenCache(context.Background())
+	return nil
+}
+
+// FlushCache 同步写入当前的 access_token 并返回写入错误，设置了 Store 时写入 Store，否则写入缓存文件。
+// 未开启持久化或尚未获取 token 时不做任何操作
+func (n *Notify) FlushCache() error {
+	n.mu.Lock()
+	cache := tokenCache{Token: n.Token, TokenExpiresAt: n.TokenExpiresAt}
+	n.mu.Unlock()
+	if cache.Token == "" {
+		return nil
+	}
+	if n.store != nil {
+		return n.saveStoreToken(cache)
+	}
+	if !n.TokenPersist {
+		return nil
+	}
+	return n.saveTokenCache(context.Background())
+}
+
+// SetUnsupportedTypeFallback 设置应用不支持 markdown 消息（第三方应用等返回错误码 40008）时，是否去除 markdown 标记后以文本消息重新发送
+func (n *Notify) SetUnsupportedTypeFallback(enabled bool) {
+	n.unsupportedTypeFallback = enabled
+}
+
+// SetSecretProvider 设置应用 secret 的获取函数，每次刷新 access_token 前调用，可用于从密钥管理服务读取轮换后的 secret。
+// 未设置时使用 New 传入的 appSecret
+func (n *Notify) SetSecretProvider(provider func() (string, error)) {
+	n.secretProvider = provider
+}
+
+// SetHTTPClient 设置发送请求使用的 HTTP 客户端，nil 时使用 10 秒超时的默认客户端
+func (n *Notify) SetHTTPClient(client *http.Client) {
+	n.httpClient = client
+}
+
+// client 返回发送请求使用的 HTTP 客户端
+func (n *Notify) client() *http.Client {
+	if n.httpClient != nil {
+		return n.httpClient
+	}
+	return &http.Client{Timeout: defaultTimeout}
 }
 
 // SetCacheFilePath 设置缓存文件路径
@@ -342,70 +906,204 @@ func (n *Notify) SetCacheFilePath(path string) {
 	n.CacheFilePath = path
 }
 
+// GetToken 获取 access_token，本地 token 未过期时直接返回
 func (n *Notify) GetToken() (string, int64, error) {
-	if n.Token != "" && time.Now().Unix() < n.TokenExpiresAt {
-		return n.Token, n.TokenExpiresAt, nil
+	return n.GetTokenContext(context.Background())
+}
+
+// GetTokenContext 获取 access_token，并发的刷新请求合并为一次，ctx 取消时等待中的调用立即返回，刷新请求本身不受影响
+func (n *Notify) GetTokenContext(ctx context.Context) (string, int64, error) {
+	token, expiresAt, _, err := n.token(ctx)
+	return token, expiresAt, err
+}
+
+// token 获取 access_token，refreshed 表示是否刷新了 token
+func (n *Notify) token(ctx context.Context) (token string, expiresAt int64, refreshed bool, err error) {
+	start := time.Now()
+	defer func() { sendInfoFrom(ctx).Timing.TokenAcquire += time.Since(start) }()
+
+	n.mu.Lock()
+	if n.Token != "" && n.now().Unix() < n.TokenExpiresAt {
+		token, expiresAt = n.Token, n.TokenExpiresAt
+		n.mu.Unlock()
+		return token, expiresAt, false, nil
+	}
+	n.mu.Unlock()
+
+	if n.store != nil {
+		cache, err := n.loadStoreToken()
+		if err == nil {
+			n.mu.Lock()
+			n.Token = cache.Token
+			n.TokenExpiresAt = cache.TokenExpiresAt
+			n.mu.Unlock()
+			return cache.Token, cache.TokenExpiresAt, false, nil
+		}
+		// store 不可用时在宽限期内继续使用内存中已过期的 token
+		if !errors.Is(err, ErrStoreNotFound) {
+			n.mu.Lock()
+			token, expiresAt = n.Token, n.TokenExpiresAt
+			n.mu.Unlock()
+			if token != "" && n.now().Before(time.Unix(expiresAt, 0).Add(n.StalenessGrace)) {
+				return token, expiresAt, false, nil
+			}
+		}
+	}
+
+	// 其他进程可能已刷新并写入缓存文件，读取失败或等待文件锁超时时重新获取
+	if n.store == nil && n.TokenPersist {
+		if err := n.loadTokenCache(ctx); err == nil {
+			n.mu.Lock()
+			token, expiresAt = n.Token, n.TokenExpiresAt
+			n.mu.Unlock()
+			return token, expiresAt, false, nil
+		}
 	}
 
-	var client = &http.Client{Timeout: 10 * time.Second}
+	_, span := n.startSpan(ctx, "notify.token_refresh")
+	ch := n.refresh.DoChan("token", func() (interface{}, error) {
+		return n.refreshToken(ctx)
+	})
+	select {
+	case <-ctx.Done():
+		span.End(ctx.Err())
+		return "", 0, false, ctx.Err()
+	case r := <-ch:
+		span.End(r.Err)
+		if r.Err != nil {
+			return "", 0, false, r.Err
+		}
+		cache := r.Val.(tokenCache)
+		n.mu.Lock()
+		n.Token = cache.Token
+		n.TokenExpiresAt = cache.TokenExpiresAt
+		n.mu.Unlock()
+		return cache.Token, cache.TokenExpiresAt, true, nil
+	}
+}
 
-	res, err := client.Get(fmt.Sprintf("%s/gettoken?corpid=%s&corpsecret=%s", apiPrefix, n.corpID, n.appSecret))
+// defaultTokenExpiryMargin TokenExpiryMargin 的默认值
+const defaultTokenExpiryMargin = 60 * time.Second
+
+// effectiveExpiresIn 返回扣除 TokenExpiryMargin 后的 token 有效秒数，预留时长不小于有效期时预留一半
+func (n *Notify) effectiveExpiresIn(expiresIn int64) int64 {
+	margin := n.TokenExpiryMargin
+	if margin == 0 {
+		margin = defaultTokenExpiryMargin
+	}
+	if margin < 0 {
+		return expiresIn
+	}
+	seconds := int64(margin / time.Second)
+	if seconds >= expiresIn {
+		return expiresIn / 2
+	}
+	return expiresIn - seconds
+}
+
+// refreshToken 请求新的 access_token 并写入缓存，ctx 仅用于等待缓存文件锁，ctx 结束时不写入缓存文件
+func (n *Notify) refreshToken(ctx context.Context) (tokenCache, error) {
+	secret := n.appSecret
+	if n.secretProvider != nil {
+		var err error
+		if secret, err = n.secretProvider(); err != nil {
+			return tokenCache{}, fmt.Errorf("get app secret error: %w", err)
+		}
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/gettoken?corpid=%s&corpsecret=%s", n.baseURL, n.corpID, secret), nil)
 	if err != nil {
-		return "", 0, fmt.Errorf("token get request error: %w", err)
+		return tokenCache{}, fmt.Errorf("token get request error: %w", err)
+	}
+	res, err := n.do(n.client(), req)
+	if err != nil {
+		return tokenCache{}, fmt.Errorf("token get request error: %w", err)
 	}
 	defer func() { _ = res.Body.Close() }()
 	var tokenRes GetTokenResult
-	err = json.NewDecoder(res.Body).Decode(&tokenRes)
+	err = n.decode(res.Body, &tokenRes)
 	if err != nil {
-		return "", 0, fmt.Errorf("token result decode error: %w", err)
+		return tokenCache{}, fmt.Errorf("token result decode error: %w", err)
 	}
 	if tokenRes.ErrorCode != 0 {
-		return "", 0, fmt.Errorf("token get error: %s", tokenRes.ErrorMsg)
+		return tokenCache{}, fmt.Errorf("token get error: %w", newAPIError(int64(tokenRes.ErrorCode), tokenRes.ErrorMsg))
 	}
-	n.Token = tokenRes.Token
-	n.TokenExpiresAt = time.Now().Unix() + tokenRes.ExpiresIn
+	cache := tokenCache{Token: tokenRes.Token, TokenExpiresAt: n.now().Unix() + n.effectiveExpiresIn(tokenRes.ExpiresIn)}
+	n.mu.Lock()
+	n.Token = cache.Token
+	n.TokenExpiresAt = cache.TokenExpiresAt
+	n.mu.Unlock()
 
-	_ = n.saveTokenCache()
+	if n.store != nil {
+		_ = n.saveStoreToken(cache)
+	} else {
+		_ = n.saveTokenCache(ctx)
+	}
 
-	return tokenRes.Token, n.TokenExpiresAt, nil
+	return cache, nil
 }
 
-func (n *Notify) loadTokenCache() error {
+// tokenCache 缓存文件内容，字段名与早期直接序列化 Notify 时保持一致以兼容已有缓存
+type tokenCache struct {
+	Token          string
+	TokenExpiresAt int64
+}
+
+// loadTokenCache 读取缓存文件中未过期的 token，读取前等待缓存文件锁，避免读到其他进程写入一半的文件。
+// ctx 结束或等待超过 CacheLockTimeout 时返回 ErrCacheLockTimeout
+func (n *Notify) loadTokenCache(ctx context.Context) error {
 	if !n.TokenPersist {
 		return fmt.Errorf("token persist not enabled")
 	}
+	if n.CacheFilePath == "" {
+		return errors.New("cache file path not set")
+	}
 
-	// 使用配置的缓存文件路径
+	ctx, cancel := n.cacheLockContext(ctx)
+	defer cancel()
+	unlock, err := n.lockCacheFile(ctx)
+	if err != nil {
+		return err
+	}
 	b, err := os.ReadFile(n.CacheFilePath)
+	unlock()
 	if err != nil {
 		return fmt.Errorf("read cache file error: %w", err)
 	}
 
-	var cache Notify
+	var cache tokenCache
 	err = json.Unmarshal(b, &cache)
 	if err != nil {
 		return fmt.Errorf("unmarshal cache data error: %w", err)
 	}
 
-	if time.Now().Unix() > cache.TokenExpiresAt {
+	if n.now().Unix() > cache.TokenExpiresAt {
 		return fmt.Errorf("token expired")
 	}
 
+	n.mu.Lock()
 	n.Token = cache.Token
 	n.TokenExpiresAt = cache.TokenExpiresAt
+	n.mu.Unlock()
 	return nil
 }
 
-func (n *Notify) saveTokenCache() error {
+// saveTokenCache 写入当前的 token，写入前等待缓存文件锁，ctx 结束或等待超过 CacheLockTimeout 时返回 ErrCacheLockTimeout
+func (n *Notify) saveTokenCache(ctx context.Context) error {
 	// 检查是否启用了令牌持久化
 	if !n.TokenPersist {
 		return fmt.Errorf("token persist not enabled")
 	}
+	if n.CacheFilePath == "" {
+		return errors.New("cache file path not set")
+	}
 
-	// 将 Notify 对象序列化为 JSON
-	b, err := json.Marshal(n)
+	// 将 token 序列化为 JSON
+	n.mu.Lock()
+	cache := tokenCache{Token: n.Token, TokenExpiresAt: n.TokenExpiresAt}
+	n.mu.Unlock()
+	b, err := json.Marshal(cache)
 	if err != nil {
-		return fmt.Errorf("marshal notify object failed: %w", err)
+		return fmt.Errorf("marshal token cache failed: %w", err)
 	}
 
 	// 确保缓存目录存在
@@ -416,6 +1114,15 @@ func (n *Notify) saveTokenCache() error {
 		}
 	}
 
+	// 多个进程共享缓存文件时串行写入，等待超时则放弃写入
+	ctx, cancel := n.cacheLockContext(ctx)
+	defer cancel()
+	unlock, err := n.lockCacheFile(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// 创建临时文件
 	tempFile := n.CacheFilePath + ".tmp"
 	f, err := os.Create(tempFile)
@@ -450,47 +1157,198 @@ func (n *Notify) saveTokenCache() error {
 	return err
 }
 
-func (n *Notify) sendMessage(msgBody map[string]interface{}) (MessageResult, error) {
-	var result MessageResult
-	var client = &http.Client{Timeout: 10 * time.Second}
-
-	body := new(bytes.Buffer)
-	err := json.NewEncoder(body).Encode(msgBody)
-	if err != nil {
-		return result, fmt.Errorf("encode message error: %w", err)
+// checkBodySize 校验请求体大小不超过 MaxBodySize
+func (n *Notify) checkBodySize(size int) error {
+	max := n.MaxBodySize
+	if max == 0 {
+		max = defaultMaxBodySize
+	}
+	if max > 0 && int64(size) > max {
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrBodyTooLarge, size, max)
 	}
-	res, err := client.Post(fmt.Sprintf("%s/message/send?access_token=%s", apiPrefix, n.Token), "application/json", body)
+	return nil
+}
+
+func (n *Notify) sendMessage(ctx context.Context, token string, msgBody map[string]interface{}) (MessageResult, error) {
+	start := time.Now()
+	defer func() { sendInfoFrom(ctx).Timing.HTTP += time.Since(start) }()
+
+	var result MessageResult
+	res, err := n.postMessage(ctx, token, msgBody)
 	if err != nil {
-		return result, fmt.Errorf("send message request error: %w", err)
+		return result, err
 	}
 	defer func() { _ = res.Body.Close() }()
 
-	err = json.NewDecoder(res.Body).Decode(&result)
+	err = n.decode(res.Body, &result)
 	if err != nil {
+		var decodeErr *DecodeError
+		if errors.As(err, &decodeErr) {
+			decodeErr.StatusCode = res.StatusCode
+		}
 		return result, fmt.Errorf("send message result decode error: %w", err)
 	}
 	return result, nil
 }
 
-func (n *Notify) sendInternal(msgBody map[string]interface{}) (MessageResult, error) {
+// defaultMaxBodySize MaxBodySize 的默认值，大于 8 篇 666K 字节正文的 mpnews 消息
+const defaultMaxBodySize = 8 << 20
+
+// postMessage 请求 message/send 接口并返回原始响应，调用方需关闭 Body
+func (n *Notify) postMessage(ctx context.Context, token string, msgBody map[string]interface{}) (*http.Response, error) {
+	body, err := n.codec.Marshal(msgBody)
+	if err != nil {
+		return nil, fmt.Errorf("encode message error: %w", err)
+	}
+	if err = n.checkBodySize(len(body)); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/message/send?access_token=%s", n.baseURL, token), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("send message request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := n.do(n.client(), req)
+	if err != nil {
+		return nil, fmt.Errorf("send message request error: %w", err)
+	}
+	return res, nil
+}
+
+func (n *Notify) sendInternal(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
 	var result MessageResult
+	info := sendInfoFrom(ctx)
 
-	token, _, err := n.GetToken()
+	if n.testModeEnabled() {
+		return n.recordSend(ctx, msgBody)
+	}
+	if token, ok := ctx.Value(tokenOverrideKey{}).(string); ok {
+		info.Attempts++
+		result, err := n.sendMessage(ctx, token, msgBody)
+		if err == nil && isConfigError(result.ErrorCode) {
+			err = newAPIError(result.ErrorCode, result.ErrorMsg)
+		}
+		return result, err
+	}
+
+	token, _, refreshed, err := n.token(ctx)
 	if err != nil {
 		return result, err
 	}
-	fmt.Println(token)
-	result, err = n.sendMessage(msgBody)
-	// 42001 access_token 已过期
-	// 40014 不合法的access_token
-	if err == nil && (result.ErrorCode == 42001 || result.ErrorCode == 40014) {
-		// DONE check if error is token expire error, then retry once
-		token, _, err := n.GetToken()
-		fmt.Println(token)
-		if err == nil {
-			result, err = n.sendMessage(msgBody)
+	info.TokenRefreshed = refreshed
+	info.Attempts++
+	result, err = n.sendMessage(ctx, token, msgBody)
+	// token 过期或无效时丢弃本地 token 重新获取后重试
+	retries := n.tokenRetriesFor(ctx)
+	for retry := 0; err == nil && isTokenInvalid(result.ErrorCode) && retry < retries; retry++ {
+		info.TokenRetried = true
+		spanFrom(ctx).AddEvent("token_retry")
+		n.invalidateToken(token)
+		token, _, refreshed, err = n.token(ctx)
+		if err != nil {
+			break
 		}
+		info.TokenRefreshed = info.TokenRefreshed || refreshed
+		info.Attempts++
+		result, err = n.sendMessage(ctx, token, msgBody)
+	}
+	if err == nil && isConfigError(result.ErrorCode) {
+		err = newAPIError(result.ErrorCode, result.ErrorMsg)
 	}
 
 	return result, err
 }
+
+// isTokenInvalid 42001 access_token 已过期，40014 不合法的access_token
+func isTokenInvalid(code int64) bool {
+	return code == 42001 || code == 40014
+}
+
+// maxTokenRetries token 过期重试次数上限，避免 secret 变更等情况下无限重试
+const maxTokenRetries = 3
+
+// SetTokenRetries 设置 access_token 过期或无效时重新获取 token 并重试的次数，默认 1 次，最多 3 次，0 表示不重试
+func (n *Notify) SetTokenRetries(count int) {
+	if count < 0 {
+		count = 0
+	}
+	if count > maxTokenRetries {
+		count = maxTokenRetries
+	}
+	n.tokenRetries = count
+}
+
+// invalidateToken 丢弃本地缓存的 token，token 已被其他调用刷新时不做处理
+func (n *Notify) invalidateToken(token string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.Token == token {
+		n.Token = ""
+		n.TokenExpiresAt = 0
+	}
+}
+
+// apiResult 接口通用返回的错误码及错误信息
+type apiResult struct {
+	ErrorCode int64  `json:"errcode"`
+	ErrorMsg  string `json:"errmsg"`
+}
+
+// callAPI 携带 access_token 调用接口 path，body 为 nil 时发送 GET 请求，否则以 JSON 格式 POST，返回内容解析到 result。
+// 不会自动添加 agentid，需要 agentid 的接口由调用方在 query 或 body 中设置
+func (n *Notify) callAPI(method, path string, query url.Values, body, result interface{}) error {
+	token, _, err := n.GetToken()
+	if err != nil {
+		return err
+	}
+	return n.callAPIWithToken(context.Background(), token, method, path, query, body, result)
+}
+
+// callAPIWithToken 同 callAPI，使用指定的 access_token，请求随 ctx 取消
+func (n *Notify) callAPIWithToken(ctx context.Context, token, method, path string, query url.Values, body, result interface{}) error {
+	client := n.client()
+
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("access_token", token)
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := n.codec.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("%s encode request error: %w", path, err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s?%s", n.baseURL, path, query.Encode()), reqBody)
+	if err != nil {
+		return fmt.Errorf("%s create request error: %w", path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := n.do(client, req)
+	if err != nil {
+		return fmt.Errorf("%s request error: %w", path, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	b, err := readResponse(res.Body)
+	if err != nil {
+		return fmt.Errorf("%s read result error: %w", path, err)
+	}
+	var status apiResult
+	if err = n.unmarshal(b, &status); err != nil {
+		return fmt.Errorf("%s result decode error: %w", path, err)
+	}
+	if status.ErrorCode != 0 {
+		return fmt.Errorf("%s error: %w", path, newAPIError(status.ErrorCode, status.ErrorMsg))
+	}
+	if result != nil {
+		if err = n.unmarshal(b, result); err != nil {
+			return fmt.Errorf("%s result decode error: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -60,11 +60,7 @@ var uploadCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		if r.ErrorCode == 0 {
-			fmt.Println("上传成功, MediaID: " + r.MediaID)
-		} else {
-			fmt.Println(r.ErrorMsg)
-		}
+		fmt.Println("上传成功, MediaID: " + r.MediaID)
 		return nil
 	},
 }
@@ -1,12 +1,22 @@
 package notify
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func newNotifyFromEnv() *Notify {
@@ -205,3 +215,510 @@ func TestNotify_Upload(t *testing.T) {
 		}
 	})
 }
+
+func TestMessageResult_Status(t *testing.T) {
+	tests := []struct {
+		name   string
+		result MessageResult
+		want   SendStatus
+	}{
+		{name: "OK", result: MessageResult{ErrorCode: 0, ErrorMsg: "ok"}, want: StatusOK},
+		{name: "PartialUser", result: MessageResult{ErrorCode: 0, ErrorMsg: "ok", InvalidUser: "u1|u2"}, want: StatusPartial},
+		{name: "PartialTag", result: MessageResult{ErrorCode: 0, ErrorMsg: "ok", InvalidTag: "1"}, want: StatusPartial},
+		{name: "Failed", result: MessageResult{ErrorCode: 81013, ErrorMsg: "user & party & tag all invalid", InvalidUser: "u1"}, want: StatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Status(); got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+			if got := tt.result.IsPartial(); got != (tt.want == StatusPartial) {
+				t.Errorf("IsPartial() = %v, want %v", got, tt.want == StatusPartial)
+			}
+		})
+	}
+}
+
+func TestNotify_tokenExpiryWithClock(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	current := start
+	n := New("corp", 1, "secret")
+	n.now = func() time.Time { return current }
+	n.CacheFilePath = filepath.Join(t.TempDir(), ".notify")
+	n.EnableTokenPersist()
+
+	n.Token = "cached"
+	n.TokenExpiresAt = start.Unix() + 7200
+	if err := n.saveTokenCache(context.Background()); err != nil {
+		t.Fatalf("saveTokenCache() error = %v", err)
+	}
+
+	t.Run("BeforeExpiry", func(t *testing.T) {
+		current = start.Add(time.Hour)
+		token, _, err := n.GetToken()
+		if err != nil || token != "cached" {
+			t.Errorf("GetToken() = %v, %v, want cached token", token, err)
+		}
+		if err := n.loadTokenCache(context.Background()); err != nil {
+			t.Errorf("loadTokenCache() error = %v, want no error", err)
+		}
+	})
+
+	t.Run("ExpiredCache", func(t *testing.T) {
+		current = start.Add(3 * time.Hour)
+		if err := n.loadTokenCache(context.Background()); err == nil {
+			t.Errorf("loadTokenCache() want token expired error")
+		}
+	})
+}
+
+func TestNotify_SendFailOnAllInvalid(t *testing.T) {
+	n := New("corp", 1, "secret")
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			return MessageResult{ErrorCode: 0, ErrorMsg: "ok", InvalidUser: "alice|bob"}, nil
+		}
+	})
+	tests := []struct {
+		name     string
+		receiver MessageReceiver
+		options  *MessageOptions
+		wantErr  bool
+	}{
+		{name: "DefaultBehavior", receiver: MessageReceiver{ToUser: "Alice|bob"}, options: nil, wantErr: false},
+		{name: "AllInvalid", receiver: MessageReceiver{ToUser: "Alice|bob"}, options: &MessageOptions{FailOnAllInvalid: true}, wantErr: true},
+		{name: "SomeValid", receiver: MessageReceiver{ToUser: "alice|carol"}, options: &MessageOptions{FailOnAllInvalid: true}, wantErr: false},
+		{name: "PartyStillValid", receiver: MessageReceiver{ToUser: "alice", ToParty: "2"}, options: &MessageOptions{FailOnAllInvalid: true}, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := n.Send(tt.receiver, Text{Content: "hi"}, tt.options)
+			if (err == ErrAllReceiversInvalid) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotify_UploadMaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gettoken" {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			return
+		}
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"file","media_id":"media","created_at":"1380000000"}`))
+	}))
+	defer server.Close()
+
+	n := New("upload", 1, "secret")
+	n.baseURL = server.URL
+	n.UploadConfig = UploadConfig{Timeout: time.Second, MaxSize: 8}
+
+	path := filepath.Join(t.TempDir(), "media.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.Upload(UploadMedia{Type: "file", Path: path}); err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("Upload() error = %v, want size limit error", err)
+	}
+	if _, err := n.UploadReader("file", "media.txt", strings.NewReader("0123456789")); err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("UploadReader() error = %v, want size limit error", err)
+	}
+	r, err := n.UploadReader("file", "media.txt", strings.NewReader("01234567"))
+	if err != nil || r.MediaID != "media" {
+		t.Errorf("UploadReader() = %v, %v, want media id", r, err)
+	}
+}
+
+func TestNotify_GetTokenContextSingleflight(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+	}))
+	defer server.Close()
+
+	n := New("singleflight", 1, "secret")
+	n.baseURL = server.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, _, err := n.GetTokenContext(context.Background()); err != nil || token != "token" {
+				t.Errorf("GetTokenContext() = %v, %v, want token", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if hits != 1 {
+		t.Errorf("gettoken requests = %d, want 1", hits)
+	}
+
+	n.Token = ""
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := n.GetTokenContext(ctx); err != context.Canceled {
+		t.Errorf("GetTokenContext() canceled error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestNotify_GetTokenContextPerClient(t *testing.T) {
+	newServer := func(token string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(20 * time.Millisecond)
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"` + token + `","expires_in":7200}`))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	// 相同 corpID 及 secret 但接口地址不同的客户端不共享刷新结果
+	a, b := New("per-client", 1, "secret"), New("per-client", 1, "secret")
+	a.baseURL, b.baseURL = newServer("token-a").URL, newServer("token-b").URL
+
+	var wg sync.WaitGroup
+	for _, c := range []struct {
+		n    *Notify
+		want string
+	}{{a, "token-a"}, {b, "token-b"}} {
+		c := c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, _, err := c.n.GetTokenContext(context.Background()); err != nil || token != c.want {
+				t.Errorf("GetTokenContext() = %v, %v, want %s", token, err, c.want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNotify_UploadContentLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gettoken" {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			return
+		}
+		chunked := len(r.TransferEncoding) > 0
+		f, h, err := r.FormFile("media")
+		if err != nil {
+			t.Errorf("FormFile() error = %v", err)
+			return
+		}
+		b, _ := io.ReadAll(f)
+		_, _ = fmt.Fprintf(w, `{"errcode":0,"errmsg":"ok","type":"file","media_id":"%s:%s:%t:%d"}`, h.Filename, b, chunked, r.ContentLength)
+	}))
+	defer server.Close()
+
+	n := New("content-length", 1, "secret")
+	n.baseURL = server.URL
+
+	path := filepath.Join(t.TempDir(), "media.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := n.Upload(UploadMedia{Type: "file", Path: path})
+	if err != nil || !strings.HasPrefix(r.MediaID, "media.txt:0123456789:false:") || strings.HasSuffix(r.MediaID, ":-1") {
+		t.Errorf("Upload() = %v, %v, want sized upload", r.MediaID, err)
+	}
+	r, err = n.Upload(UploadMedia{Type: "file", Path: path, Filename: "report.pdf"})
+	if err != nil || !strings.HasPrefix(r.MediaID, "report.pdf:") {
+		t.Errorf("Upload() = %v, %v, want filename report.pdf", r.MediaID, err)
+	}
+	r, err = n.UploadReader("file", "reader.txt", strings.NewReader("abc"))
+	if err != nil || r.MediaID != "reader.txt:abc:true:-1" {
+		t.Errorf("UploadReader() = %v, %v, want chunked upload", r.MediaID, err)
+	}
+}
+
+// newTestNotify 创建连接到本地测试服务的 Notify，gettoken 固定返回有效 token，其余请求交给 handler 处理
+func newTestNotify(t *testing.T, handler http.HandlerFunc) *Notify {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gettoken" {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	n := New(t.Name(), 1, "secret")
+	n.baseURL = server.URL
+	return n
+}
+
+func TestNotify_SetTokenRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		retries   int
+		failures  int32
+		wantCode  int64
+		wantCalls int32
+	}{
+		{name: "DefaultOnce", retries: -1, failures: 1, wantCode: 0, wantCalls: 2},
+		{name: "DefaultStillExpired", retries: -1, failures: 2, wantCode: 42001, wantCalls: 2},
+		{name: "Twice", retries: 2, failures: 2, wantCode: 0, wantCalls: 3},
+		{name: "Disabled", retries: 0, failures: 1, wantCode: 42001, wantCalls: 1},
+		{name: "Capped", retries: 100, failures: 100, wantCode: 42001, wantCalls: 1 + maxTokenRetries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) <= tt.failures {
+					_, _ = w.Write([]byte(`{"errcode":42001,"errmsg":"access_token expired"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+			})
+			if tt.retries >= 0 {
+				n.SetTokenRetries(tt.retries)
+			}
+			got, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+			if err != nil {
+				t.Fatalf("Send() error = %v, want no error", err)
+			}
+			if got.ErrorCode != tt.wantCode || calls != tt.wantCalls {
+				t.Errorf("Send() errcode = %d after %d calls, want %d after %d calls", got.ErrorCode, calls, tt.wantCode, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestNotify_SendMsgID(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","msgid":"msg-1"}`))
+	})
+
+	got, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if got.MsgID != "msg-1" {
+		t.Errorf("Send() msgid = %q, want %q", got.MsgID, "msg-1")
+	}
+}
+
+func TestNotify_SendTTL(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	start := time.Now()
+	_, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, &MessageOptions{TTL: 50 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Send() took %v, want abort after ttl", elapsed)
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	defaults := &MessageOptions{EnableDuplicateCheck: true, DuplicateCheckInterval: 300}
+	tests := []struct {
+		name     string
+		defaults *MessageOptions
+		options  *MessageOptions
+		want     *MessageOptions
+	}{
+		{"no defaults", nil, &MessageOptions{Safe: true}, &MessageOptions{Safe: true}},
+		{"nil options", defaults, nil, defaults},
+		{"per-call wins", defaults, &MessageOptions{Safe: true, DuplicateCheckInterval: 60}, &MessageOptions{Safe: true, EnableDuplicateCheck: true, DuplicateCheckInterval: 60}},
+		{"force disable duplicate check", defaults, &MessageOptions{ForceDisableDuplicateCheck: true}, &MessageOptions{ForceDisableDuplicateCheck: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeOptions(tt.defaults, tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if defaults.Safe {
+		t.Errorf("mergeOptions() modified defaults")
+	}
+}
+
+func TestNotify_SetSecretProvider(t *testing.T) {
+	var secrets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secrets = append(secrets, r.URL.Query().Get("corpsecret"))
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"token","expires_in":7200}`))
+	}))
+	defer server.Close()
+
+	n := New(t.Name(), 1, "static")
+	n.baseURL = server.URL
+	if _, err := n.refreshToken(context.Background()); err != nil {
+		t.Fatalf("refreshToken() error = %v, want no error", err)
+	}
+	n.SetSecretProvider(func() (string, error) { return "rotated", nil })
+	if _, err := n.refreshToken(context.Background()); err != nil {
+		t.Fatalf("refreshToken() error = %v, want no error", err)
+	}
+	if !reflect.DeepEqual(secrets, []string{"static", "rotated"}) {
+		t.Errorf("secrets = %v, want [static rotated]", secrets)
+	}
+
+	errVault := errors.New("vault sealed")
+	n.SetSecretProvider(func() (string, error) { return "", errVault })
+	if _, err := n.refreshToken(context.Background()); !errors.Is(err, errVault) {
+		t.Errorf("refreshToken() error = %v, want %v", err, errVault)
+	}
+}
+
+func TestNotify_requireAgent(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("agentid") != "" {
+			t.Errorf("query agentid = %s, want not set", r.URL.Query().Get("agentid"))
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","taglist":[]}`))
+	})
+	n.agentID = 0
+
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != ErrNoAgentID {
+		t.Errorf("Send() error = %v, want %v", err, ErrNoAgentID)
+	}
+	if err := n.DeleteMaterial("m1"); err != ErrNoAgentID {
+		t.Errorf("DeleteMaterial() error = %v, want %v", err, ErrNoAgentID)
+	}
+	if _, err := n.ListTags(); err != nil {
+		t.Errorf("ListTags() error = %v, want no error", err)
+	}
+}
+
+func TestNotify_UploadAPIError(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":40004,"errmsg":"invalid media type"}`))
+	})
+
+	r, err := n.UploadReader("unknown", "a.txt", strings.NewReader("abc"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 40004 {
+		t.Fatalf("UploadReader() error = %v, want APIError 40004", err)
+	}
+	if r.ErrorCode != 40004 {
+		t.Errorf("UploadReader() result ErrorCode = %d, want 40004", r.ErrorCode)
+	}
+}
+
+func TestMessageResult_InvalidReceivers(t *testing.T) {
+	r := MessageResult{ErrorCode: 0, InvalidUser: "a|b", InvalidTag: "3"}
+	want := map[string][]string{"user": {"a", "b"}, "tag": {"3"}}
+	if got := r.InvalidReceivers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("InvalidReceivers() = %v, want %v", got, want)
+	}
+	if !r.HasInvalid() || r.InvalidParties() != nil {
+		t.Errorf("HasInvalid() = %v, InvalidParties() = %v, want true and nil", r.HasInvalid(), r.InvalidParties())
+	}
+	if (MessageResult{}).HasInvalid() || len((MessageResult{}).InvalidReceivers()) != 0 {
+		t.Errorf("empty result want no invalid receivers")
+	}
+}
+
+func TestNotify_EnableTokenPersist(t *testing.T) {
+	n := New(t.Name(), 1, "secret")
+	n.SetCacheFilePath("")
+	if err := n.EnableTokenPersistE(); err == nil || n.TokenPersist {
+		t.Errorf("EnableTokenPersistE() error = %v, persist = %v, want error and not enabled", err, n.TokenPersist)
+	}
+
+	path := filepath.Join(t.TempDir(), "cache")
+	expiresAt := time.Now().Unix() + 3600
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(`{"Token":"cached","TokenExpiresAt":%d}`, expiresAt)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n.SetCacheFilePath(path)
+	if err := n.EnableTokenPersistE(); err != nil {
+		t.Fatalf("EnableTokenPersistE() error = %v, want no error", err)
+	}
+	if n.Token != "cached" || n.TokenExpiresAt != expiresAt {
+		t.Errorf("EnableTokenPersistE() token = %q, expires at %d, want loaded from cache", n.Token, n.TokenExpiresAt)
+	}
+}
+
+func TestNotify_FlushCache(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {})
+	n.SetCacheFilePath(filepath.Join(t.TempDir(), "cache"))
+	if _, _, err := n.GetToken(); err != nil {
+		t.Fatalf("GetToken() error = %v, want no error", err)
+	}
+	if err := n.FlushCache(); err != nil {
+		t.Errorf("FlushCache() without persist error = %v, want nil", err)
+	}
+	if _, err := os.Stat(n.CacheFilePath); !os.IsNotExist(err) {
+		t.Errorf("cache file error = %v, want not written", err)
+	}
+
+	if err := n.EnableTokenPersistE(); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.FlushCache(); err != nil {
+		t.Fatalf("FlushCache() error = %v, want no error", err)
+	}
+	b, err := os.ReadFile(n.CacheFilePath)
+	if err != nil || !strings.Contains(string(b), `"Token":"token"`) {
+		t.Errorf("cache file = %s, %v, want token", b, err)
+	}
+
+	n.CacheFilePath = filepath.Join(n.CacheFilePath, "not-a-dir", "cache")
+	if err := n.FlushCache(); err == nil {
+		t.Errorf("FlushCache() want error for unwritable path")
+	}
+}
+
+func TestNotify_SetTypeOptions(t *testing.T) {
+	n := New(t.Name(), 1, "secret")
+	n.DefaultOptions = &MessageOptions{Safe: true}
+	n.SetTypeOptions("text", &MessageOptions{EnableDuplicateCheck: true, DuplicateCheckInterval: 300})
+
+	want := &MessageOptions{Safe: true, EnableDuplicateCheck: true, DuplicateCheckInterval: 300}
+	if got := n.defaultOptions(Text{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("defaultOptions(Text) = %+v, want %+v", got, want)
+	}
+	if got := n.defaultOptions(TaskCard{}); got != n.DefaultOptions {
+		t.Errorf("defaultOptions(TaskCard) = %+v, want client default", got)
+	}
+
+	n.SetTypeOptions("text", nil)
+	if got := n.defaultOptions(Text{}); got != n.DefaultOptions {
+		t.Errorf("defaultOptions(Text) after delete = %+v, want client default", got)
+	}
+}
+
+func TestNotify_TokenExpiryMargin(t *testing.T) {
+	tests := []struct {
+		name   string
+		margin time.Duration
+		want   int64
+	}{
+		{name: "Default", margin: 0, want: 7140},
+		{name: "Custom", margin: 5 * time.Minute, want: 6900},
+		{name: "Disabled", margin: -1, want: 7200},
+		{name: "TooLarge", margin: 3 * time.Hour, want: 3600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newTestNotify(t, nil)
+			now := time.Unix(1657350000, 0)
+			n.now = func() time.Time { return now }
+			n.TokenExpiryMargin = tt.margin
+
+			if _, _, err := n.GetToken(); err != nil {
+				t.Fatalf("GetToken() error = %v, want no error", err)
+			}
+			if got := n.TokenExpiresAt - now.Unix(); got != tt.want {
+				t.Errorf("GetToken() expires in %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// UserInfo 网页授权登录获取的访问用户身份，企业成员返回 UserID，非企业成员返回 OpenID 或 ExternalUserID
+type UserInfo struct {
+	UserID         string `json:"userid"`          // 企业成员 userid
+	UserTicket     string `json:"user_ticket"`     // 成员票据，scope 为 snsapi_privateinfo 时返回
+	OpenID         string `json:"openid"`          // 非企业成员的标识
+	ExternalUserID string `json:"external_userid"` // 外部联系人 id
+}
+
+// GetUserInfoByCode 根据网页授权回调中的 code 获取访问用户身份，code 只能使用一次，5 分钟内有效
+func (n *Notify) GetUserInfoByCode(code string) (UserInfo, error) {
+	var result UserInfo
+	if code == "" {
+		return result, errors.New("code can not be empty")
+	}
+	query := url.Values{}
+	query.Set("code", code)
+	err := n.callAPI(http.MethodGet, "auth/getuserinfo", query, nil, &result)
+	return result, err
+}
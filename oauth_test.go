@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNotify_GetUserInfoByCode(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth/getuserinfo" || r.URL.Query().Get("code") != "c1" {
+			_, _ = w.Write([]byte(`{"errcode":40029,"errmsg":"invalid code"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","userid":"zhangsan","user_ticket":"ticket"}`))
+	})
+
+	info, err := n.GetUserInfoByCode("c1")
+	if err != nil {
+		t.Fatalf("GetUserInfoByCode() error = %v, want no error", err)
+	}
+	if info.UserID != "zhangsan" || info.UserTicket != "ticket" {
+		t.Errorf("GetUserInfoByCode() = %+v, want userid zhangsan", info)
+	}
+	if _, err := n.GetUserInfoByCode("c2"); err == nil {
+		t.Errorf("GetUserInfoByCode() want error for invalid code")
+	}
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSendQueued 熔断期间消息已加入故障队列，熔断恢复后重新发送
+var ErrSendQueued = errors.New("circuit breaker is open, message queued for retry")
+
+// outageQueue 熔断期间暂存的消息，每项为 Marshal 生成的请求体
+type outageQueue struct {
+	mu       sync.Mutex
+	max      int
+	items    [][]byte
+	flushing int32
+}
+
+// EnableOutageQueue 开启故障队列，熔断期间的发送不直接失败，而是加入队列并返回 ErrSendQueued，
+// 熔断恢复后的第一次发送成功时按入队顺序重新发送，也可以调用 FlushOutageQueue 手动发送。
+// 设置了 Store 时队列保存在 Store 中，进程重启后不丢失，多个进程同时读写时可能重复或丢失，顺序也不做保证。
+// 队列最多保存 max 条消息，已满时返回 ErrCircuitOpen，max 不大于 0 时关闭故障队列。
+// 入队时只保存接收人、消息及接口参数，Priority、TTL 等非接口参数不会保留，RawMessage 等无法还原的消息不入队，直接返回 ErrCircuitOpen
+func (n *Notify) EnableOutageQueue(max int) {
+	n.outage.mu.Lock()
+	n.outage.max = max
+	n.outage.mu.Unlock()
+}
+
+// enqueueOutage 将消息加入故障队列，未开启、队列已满或消息无法编码时返回 false。
+// Unmarshal 无法还原的消息（如 RawMessage）重发时无法发送，不加入队列
+func (n *Notify) enqueueOutage(receiver MessageReceiver, message interface{}, options *MessageOptions) bool {
+	b, err := Marshal(receiver, message, options, n.agentID)
+	if err != nil {
+		return false
+	}
+	if _, _, _, _, err = Unmarshal(b); err != nil {
+		return false
+	}
+
+	n.outage.mu.Lock()
+	defer n.outage.mu.Unlock()
+	if n.outage.max <= 0 {
+		return false
+	}
+	items := n.loadOutageItems()
+	if len(items) >= n.outage.max {
+		return false
+	}
+	return n.saveOutageItems(append(items, b)) == nil
+}
+
+// FlushOutageQueue 按入队顺序重新发送故障队列中的消息，返回发送成功的数量。
+// 熔断仍未恢复时停止发送，未发送的消息保留在队列中。队列中无法解析的消息被丢弃，发送完成后返回包含丢弃数量的错误
+func (n *Notify) FlushOutageQueue(ctx context.Context) (int, error) {
+	n.outage.mu.Lock()
+	items := n.loadOutageItems()
+	err := n.saveOutageItems(nil)
+	n.outage.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	sent, dropped := 0, 0
+	var decodeErr error
+	for i, b := range items {
+		receiver, message, options, _, err := Unmarshal(b)
+		if err != nil {
+			if dropped++; decodeErr == nil {
+				decodeErr = err
+			}
+			continue
+		}
+		// 入队前已完成去重，重发时不再去重
+		options = withoutDedup(n.resolveOptions(ctx, message, options))
+		if _, err = n.sendResolved(ctx, receiver, message, options); errors.Is(err, ErrSendQueued) || errors.Is(err, ErrCircuitOpen) {
+			n.requeueOutage(items[i+1:])
+			return sent, err
+		}
+		if err == nil {
+			sent++
+		}
+	}
+	if dropped > 0 {
+		return sent, fmt.Errorf("drop %d queued messages error: %w", dropped, decodeErr)
+	}
+	return sent, nil
+}
+
+// flushOutageAsync 熔断恢复后在后台发送故障队列中的消息，同一时间只有一个发送
+func (n *Notify) flushOutageAsync() {
+	n.outage.mu.Lock()
+	empty := n.outage.max <= 0 || len(n.loadOutageItems()) == 0
+	n.outage.mu.Unlock()
+	if empty || !atomic.CompareAndSwapInt32(&n.outage.flushing, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&n.outage.flushing, 0)
+		_, _ = n.FlushOutageQueue(context.Background())
+	}()
+}
+
+// requeueOutage 将未发送的消息放回队列
+func (n *Notify) requeueOutage(rest [][]byte) {
+	if len(rest) == 0 {
+		return
+	}
+	n.outage.mu.Lock()
+	defer n.outage.mu.Unlock()
+	_ = n.saveOutageItems(append(n.loadOutageItems(), rest...))
+}
+
+// loadOutageItems 读取队列，设置了 Store 时从 Store 读取，调用方需持有 outage.mu
+func (n *Notify) loadOutageItems() [][]byte {
+	if n.store == nil {
+		return n.outage.items
+	}
+	b, err := n.store.Get(n.storeKey("outage"))
+	if err != nil {
+		return nil
+	}
+	var items []json.RawMessage
+	if err = json.Unmarshal(b, &items); err != nil {
+		return nil
+	}
+	result := make([][]byte, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result
+}
+
+// saveOutageItems 保存队列，设置了 Store 时写入 Store，调用方需持有 outage.mu
+func (n *Notify) saveOutageItems(items [][]byte) error {
+	if n.store == nil {
+		n.outage.items = items
+		return nil
+	}
+	raw := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		raw[i] = item
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return n.store.Set(n.storeKey("outage"), b, 0)
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_EnableOutageQueue(t *testing.T) {
+	var down int32 = 1
+	var mu sync.Mutex
+	var delivered []string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			return
+		}
+		var body struct {
+			Text Text `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		delivered = append(delivered, body.Text.Content)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	now := time.Now()
+	n.now = func() time.Time { return now }
+	n.EnableCircuitBreaker(1, time.Minute)
+	n.EnableOutageQueue(2)
+
+	send := func(content string) error {
+		_, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: content}, nil)
+		return err
+	}
+	_ = send("fail")
+	for _, content := range []string{"a", "b"} {
+		if err := send(content); !errors.Is(err, ErrSendQueued) {
+			t.Fatalf("Send() error = %v, want %v", err, ErrSendQueued)
+		}
+	}
+	if err := send("c"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Send() error = %v, want %v when queue is full", err, ErrCircuitOpen)
+	}
+
+	// 熔断恢复后发送成功，后台重新发送队列中的消息
+	now = now.Add(time.Minute)
+	atomic.StoreInt32(&down, 0)
+	if err := send("d"); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	want := []string{"d", "a", "b"}
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		got := append([]string(nil), delivered...)
+		mu.Unlock()
+		if reflect.DeepEqual(got, want) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivered = %v, want %v", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNotify_FlushOutageQueueStore(t *testing.T) {
+	var sent int32
+	store := NewMemoryStore()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}
+	first := newTestNotify(t, handler)
+	first.SetStore(store)
+	first.EnableOutageQueue(10)
+	if !first.enqueueOutage(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil) {
+		t.Fatal("enqueueOutage() = false, want true")
+	}
+
+	// 队列保存在 Store 中，由新的客户端发送
+	second := newTestNotify(t, handler)
+	second.SetStore(store)
+	second.EnableOutageQueue(10)
+	got, err := second.FlushOutageQueue(context.Background())
+	if err != nil || got != 1 || atomic.LoadInt32(&sent) != 1 {
+		t.Errorf("FlushOutageQueue() = %d, %v after %d sends, want 1 sent", got, err, sent)
+	}
+	if got, _ = second.FlushOutageQueue(context.Background()); got != 0 {
+		t.Errorf("FlushOutageQueue() = %d, want empty queue", got)
+	}
+}
+
+func TestNotify_OutageQueueUnreplayable(t *testing.T) {
+	var sent int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.EnableOutageQueue(10)
+
+	// RawMessage 无法由 Unmarshal 还原，不加入队列
+	raw := RawMessage{MsgType: "markdown_v2", Content: map[string]string{"content": "hi"}}
+	if n.enqueueOutage(MessageReceiver{ToUser: "@all"}, raw, nil) {
+		t.Errorf("enqueueOutage() = true, want RawMessage refused")
+	}
+
+	// 队列中无法解析的消息计入错误，不静默丢弃
+	n.outage.items = [][]byte{[]byte(`{"touser":"@all","msgtype":"markdown_v2","markdown_v2":{"content":"hi"}}`)}
+	if !n.enqueueOutage(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil) {
+		t.Fatal("enqueueOutage() = false, want true")
+	}
+	got, err := n.FlushOutageQueue(context.Background())
+	if got != 1 || !errors.Is(err, ErrUnsupportedMessageType) {
+		t.Errorf("FlushOutageQueue() = %d, %v, want 1 sent and the dropped message reported", got, err)
+	}
+	if atomic.LoadInt32(&sent) != 1 {
+		t.Errorf("sends = %d, want 1", sent)
+	}
+}
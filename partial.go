@@ -0,0 +1,28 @@
+package notify
+
+import "context"
+
+type partialRetryKey struct{}
+
+// OnPartialFailure 设置部分接收人无效时的回调，返回 true 时向无效的接收人重新发送一次，
+// 用于处理企业微信临时故障导致的误判。重发结果中的无效接收人替换原结果中的无效接收人，重发失败时返回原结果。
+// 未设置时不重发
+func (n *Notify) OnPartialFailure(hook func(result MessageResult) (retry bool)) {
+	n.onPartialFailure = hook
+}
+
+// retryPartial 在 OnPartialFailure 返回 true 时向无效的接收人重发，重发本身不再触发回调
+func (n *Notify) retryPartial(ctx context.Context, result MessageResult, message interface{}, options *MessageOptions) MessageResult {
+	if n.onPartialFailure == nil || ctx.Value(partialRetryKey{}) != nil || !n.onPartialFailure(result) {
+		return result
+	}
+	receiver := MessageReceiver{ToUser: result.InvalidUser, ToParty: result.InvalidParty, ToTag: result.InvalidTag}
+	retried, err := n.sendResolved(context.WithValue(ctx, partialRetryKey{}, true), receiver, message, options)
+	if err != nil {
+		return result
+	}
+	result.InvalidUser = retried.InvalidUser
+	result.InvalidParty = retried.InvalidParty
+	result.InvalidTag = retried.InvalidTag
+	return result
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestNotify_OnPartialFailure(t *testing.T) {
+	var receivers []string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		var body MessageReceiver
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		receivers = append(receivers, body.ToUser)
+		if len(receivers) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","invaliduser":"bob"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	var calls int
+	n.OnPartialFailure(func(result MessageResult) bool {
+		calls++
+		return result.InvalidUser == "bob"
+	})
+	result, err := n.Send(MessageReceiver{ToUser: "alice|bob"}, Text{Content: "hi"}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if result.HasInvalid() || calls != 1 || len(receivers) != 2 || receivers[1] != "bob" {
+		t.Errorf("Send() = %+v, hook calls = %d, receivers = %v, want retry to bob only", result, calls, receivers)
+	}
+
+	n.OnPartialFailure(nil)
+	receivers = nil
+	if result, _ = n.Send(MessageReceiver{ToUser: "alice|bob"}, Text{Content: "hi"}, nil); result.InvalidUser != "bob" || len(receivers) != 1 {
+		t.Errorf("Send() = %+v, receivers = %v, want no retry by default", result, receivers)
+	}
+}
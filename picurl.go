@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// maxPicBytes 图文消息图片的大小上限，与图片素材的限制一致
+const maxPicBytes = 2 << 20
+
+// ValidatePicURLs 检查图文消息每条图文的 PicURL 能否访问，且为不超过2MB的 JPG 或 PNG 图片，返回每个无效链接的错误，全部有效时返回 nil。
+// 企业微信按链接拉取图片，发送前检查可以提前发现失效的图片链接，不校验图片尺寸
+func (n *Notify) ValidatePicURLs(news News) []error {
+	var errs []error
+	for i, article := range news.Articles {
+		if article.PicURL == "" {
+			continue
+		}
+		if err := n.checkPicURL(article.PicURL); err != nil {
+			errs = append(errs, fmt.Errorf("article %d picurl %s error: %w", i, article.PicURL, err))
+		}
+	}
+	return errs
+}
+
+// checkPicURL 通过 HEAD 请求检查图片链接，服务端不支持 HEAD 时使用 GET 请求
+func (n *Notify) checkPicURL(url string) error {
+	res, err := n.requestPic(http.MethodHead, url)
+	if err == nil && (res.StatusCode == http.StatusMethodNotAllowed || res.StatusCode == http.StatusNotImplemented) {
+		res, err = n.requestPic(http.MethodGet, url)
+	}
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "image/jpeg" && mediaType != "image/png" {
+		return fmt.Errorf("unsupported content type %q", res.Header.Get("Content-Type"))
+	}
+	if res.ContentLength > maxPicBytes {
+		return fmt.Errorf("image size %d exceeds limit %d", res.ContentLength, maxPicBytes)
+	}
+	return nil
+}
+
+// requestPic 请求图片链接并关闭返回的 Body，只保留状态码及响应头
+func (n *Notify) requestPic(method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := n.do(n.client(), req)
+	if err != nil {
+		return nil, err
+	}
+	_ = res.Body.Close()
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestNotify_ValidatePicURLs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok.png":
+			w.Header().Set("Content-Type", "image/png")
+		case "/get-only.jpg":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "image/jpeg")
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		case "/large.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", strconv.Itoa(maxPicBytes+1))
+			return
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	n := New(t.Name(), 1, "secret")
+	news := News{Articles: []NewsArticle{
+		{Title: "ok", PicURL: srv.URL + "/ok.png"},
+		{Title: "no pic"},
+		{Title: "get only", PicURL: srv.URL + "/get-only.jpg"},
+		{Title: "html", PicURL: srv.URL + "/page.html"},
+		{Title: "large", PicURL: srv.URL + "/large.png"},
+		{Title: "missing", PicURL: srv.URL + "/missing.png"},
+	}}
+	if errs := n.ValidatePicURLs(news); len(errs) != 3 {
+		t.Errorf("ValidatePicURLs() = %v, want 3 errors", errs)
+	}
+	if errs := n.ValidatePicURLs(News{Articles: news.Articles[:3]}); errs != nil {
+		t.Errorf("ValidatePicURLs() = %v, want nil", errs)
+	}
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Priority 消息优先级，通过 MessageOptions.Priority 指定
+type Priority int
+
+const (
+	PriorityNormal   Priority = iota // 普通消息，默认
+	PriorityCritical                 // 紧急消息，不受免打扰时段限制
+)
+
+// QuietAction 免打扰时段内普通消息的处理方式
+type QuietAction int
+
+const (
+	QuietDrop  QuietAction = iota // 丢弃消息
+	QuietDefer                    // 延迟到免打扰时段结束时发送
+)
+
+var (
+	// ErrQuietHoursDropped 免打扰时段内的普通消息已被丢弃
+	ErrQuietHoursDropped = errors.New("message dropped during quiet hours")
+	// ErrQuietHoursDeferred 免打扰时段内的普通消息将在时段结束时发送
+	ErrQuietHoursDeferred = errors.New("message deferred until quiet hours end")
+)
+
+// SendPolicy 发送策略。QuietStart 与 QuietEnd 为距当天零点的时长，
+// 如 22:00-08:00 为 QuietStart: 22 * time.Hour, QuietEnd: 8 * time.Hour。两者相等时不限制，即默认允许全部发送
+type SendPolicy struct {
+	QuietStart time.Duration  // 免打扰开始时间
+	QuietEnd   time.Duration  // 免打扰结束时间，小于 QuietStart 表示跨越零点
+	Action     QuietAction    // 免打扰时段内普通消息的处理方式
+	Location   *time.Location // 时区，默认本地时区
+}
+
+// SetSendPolicy 设置发送策略
+func (n *Notify) SetSendPolicy(policy SendPolicy) {
+	n.policy = policy
+}
+
+// sinceMidnight 返回 t 在策略时区中距当天零点的时长及当天零点
+func (p SendPolicy) sinceMidnight(t time.Time) (time.Duration, time.Time) {
+	if p.Location != nil {
+		t = t.In(p.Location)
+	} else {
+		t = t.Local()
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return t.Sub(midnight), midnight
+}
+
+// quiet 判断 t 是否处于免打扰时段
+func (p SendPolicy) quiet(t time.Time) bool {
+	if p.QuietStart == p.QuietEnd {
+		return false
+	}
+	d, _ := p.sinceMidnight(t)
+	if p.QuietStart < p.QuietEnd {
+		return d >= p.QuietStart && d < p.QuietEnd
+	}
+	return d >= p.QuietStart || d < p.QuietEnd
+}
+
+// quietEnd 返回 t 之后最近的免打扰结束时间
+func (p SendPolicy) quietEnd(t time.Time) time.Time {
+	_, midnight := p.sinceMidnight(t)
+	end := midnight.Add(p.QuietEnd)
+	if !end.After(t) {
+		end = end.Add(24 * time.Hour)
+	}
+	return end
+}
+
+// applyPolicy 免打扰时段内丢弃或延迟普通消息，返回非 nil 错误时本次不发送
+func (n *Notify) applyPolicy(receiver MessageReceiver, message interface{}, options *MessageOptions) error {
+	if options != nil && options.Priority >= PriorityCritical {
+		return nil
+	}
+	now := n.now()
+	if !n.policy.quiet(now) {
+		return nil
+	}
+	if n.policy.Action == QuietDefer {
+		// options 已合并默认配置，到期后直接发送，不再合并及去重
+		n.schedule(n.policy.quietEnd(now), func() {
+			_, _ = n.sendResolved(context.Background(), receiver, message, options)
+		})
+		return ErrQuietHoursDeferred
+	}
+	return ErrQuietHoursDropped
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSendPolicy(t *testing.T) {
+	policy := SendPolicy{QuietStart: 22 * time.Hour, QuietEnd: 8 * time.Hour, Location: time.UTC}
+	tests := []struct {
+		name      string
+		at        time.Time
+		wantQuiet bool
+		wantEnd   time.Time
+	}{
+		{name: "Evening", at: time.Date(2022, 7, 9, 23, 0, 0, 0, time.UTC), wantQuiet: true, wantEnd: time.Date(2022, 7, 10, 8, 0, 0, 0, time.UTC)},
+		{name: "EarlyMorning", at: time.Date(2022, 7, 10, 3, 0, 0, 0, time.UTC), wantQuiet: true, wantEnd: time.Date(2022, 7, 10, 8, 0, 0, 0, time.UTC)},
+		{name: "QuietEnd", at: time.Date(2022, 7, 10, 8, 0, 0, 0, time.UTC), wantQuiet: false},
+		{name: "Daytime", at: time.Date(2022, 7, 10, 12, 0, 0, 0, time.UTC), wantQuiet: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.quiet(tt.at); got != tt.wantQuiet {
+				t.Errorf("quiet() = %v, want %v", got, tt.wantQuiet)
+			}
+			if tt.wantQuiet {
+				if got := policy.quietEnd(tt.at); !got.Equal(tt.wantEnd) {
+					t.Errorf("quietEnd() = %v, want %v", got, tt.wantEnd)
+				}
+			}
+		})
+	}
+
+	if (SendPolicy{}).quiet(time.Now()) {
+		t.Errorf("default policy quiet() = true, want false")
+	}
+}
+
+func TestNotify_SetSendPolicy(t *testing.T) {
+	n := New("corp", 1, "secret")
+	n.now = func() time.Time { return time.Date(2022, 7, 9, 23, 0, 0, 0, time.UTC) }
+	n.SetSendPolicy(SendPolicy{QuietStart: 22 * time.Hour, QuietEnd: 8 * time.Hour, Location: time.UTC})
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			return MessageResult{ErrorMsg: "ok"}, nil
+		}
+	})
+	receiver := MessageReceiver{ToUser: "@all"}
+
+	if _, err := n.Send(receiver, Text{Content: "low"}, nil); err != ErrQuietHoursDropped {
+		t.Errorf("Send() error = %v, want %v", err, ErrQuietHoursDropped)
+	}
+	if _, err := n.Send(receiver, Text{Content: "page"}, &MessageOptions{Priority: PriorityCritical}); err != nil {
+		t.Errorf("Send() critical error = %v, want no error", err)
+	}
+}
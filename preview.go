@@ -0,0 +1,17 @@
+package notify
+
+import "errors"
+
+// SetTestUser 设置 SendToSelf 使用的测试成员 userid
+func (n *Notify) SetTestUser(userID string) {
+	n.testUser = userID
+}
+
+// SendToSelf 将消息只发送给测试成员，用于正式群发前预览消息在客户端的展示效果。
+// 企业微信没有只校验不投递的接口，服务端校验规则需通过实际发送确认
+func (n *Notify) SendToSelf(message interface{}) (MessageResult, error) {
+	if n.testUser == "" {
+		return MessageResult{}, errors.New("test user not set, call SetTestUser first")
+	}
+	return n.Send(MessageReceiver{ToUser: n.testUser}, message, nil)
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNotify_SendToSelf(t *testing.T) {
+	var sent string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		sent = string(b)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	if _, err := n.SendToSelf(Text{Content: "hi"}); err == nil {
+		t.Errorf("SendToSelf() without test user want error")
+	}
+
+	n.SetTestUser("me")
+	if _, err := n.SendToSelf(Text{Content: "hi"}); err != nil {
+		t.Fatalf("SendToSelf() error = %v, want no error", err)
+	}
+	if !strings.Contains(sent, `"touser":"me"`) {
+		t.Errorf("SendToSelf() body = %s, want touser me", sent)
+	}
+}
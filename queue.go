@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrQueueFull 异步队列已满，调用方应丢弃或稍后重试
+	ErrQueueFull = errors.New("async queue is full")
+	// ErrQueueClosed 异步队列已关闭
+	ErrQueueClosed = errors.New("async queue is closed")
+)
+
+// AsyncQueue 有界的异步发送队列，由固定数量的 worker 发送，队列满时 Enqueue 立即返回 ErrQueueFull，
+// 用于告警风暴时限制 goroutine 及内存占用。发送结果通过 OnSend 注册的回调获取
+type AsyncQueue struct {
+	n      *Notify
+	jobs   chan BatchJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// AsyncQueue 创建并启动异步发送队列，workers 为并发发送数，默认 1，bufferSize 为队列容量
+func (n *Notify) AsyncQueue(workers, bufferSize int) *AsyncQueue {
+	if workers <= 0 {
+		workers = 1
+	}
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+	q := &AsyncQueue{n: n, jobs: make(chan BatchJob, bufferSize)}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// work 依次发送队列中的消息直到队列关闭
+func (q *AsyncQueue) work() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		_, _ = q.n.SendContext(q.ctx, job.Receiver, job.Message, job.Options)
+	}
+}
+
+// Enqueue 将消息加入队列，不会阻塞，队列满时返回 ErrQueueFull，关闭后返回 ErrQueueClosed
+func (q *AsyncQueue) Enqueue(job BatchJob) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Len 返回队列中等待发送的消息数
+func (q *AsyncQueue) Len() int {
+	return len(q.jobs)
+}
+
+// Shutdown 关闭队列并等待已入队的消息发送完成，ctx 取消时中止未完成的发送并返回 ctx 的错误
+func (q *AsyncQueue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		return ctx.Err()
+	}
+}
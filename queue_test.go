@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncQueue(t *testing.T) {
+	var sent int32
+	release := make(chan struct{})
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		atomic.AddInt32(&sent, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	q := n.AsyncQueue(1, 2)
+	job := BatchJob{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}}
+	var full bool
+	for i := 0; i < 4; i++ {
+		if err := q.Enqueue(job); errors.Is(err, ErrQueueFull) {
+			full = true
+		} else if err != nil {
+			t.Fatalf("Enqueue() error = %v, want no error", err)
+		}
+	}
+	if !full {
+		t.Errorf("Enqueue() want %v when buffer is full", ErrQueueFull)
+	}
+
+	close(release)
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v, want no error", err)
+	}
+	if got := atomic.LoadInt32(&sent); got < 2 || got > 3 {
+		t.Errorf("Shutdown() sent %d messages, want all enqueued messages", got)
+	}
+	if err := q.Enqueue(job); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Enqueue() after Shutdown error = %v, want %v", err, ErrQueueClosed)
+	}
+}
+
+func TestAsyncQueue_ShutdownTimeout(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	q := n.AsyncQueue(1, 1)
+	_ = q.Enqueue(BatchJob{Receiver: MessageReceiver{ToUser: "@all"}, Message: Text{Content: "hi"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := q.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sendCountTTL 发送计数在 Store 中的有效期，超过一天以免时区差异导致提前过期
+const sendCountTTL = 48 * time.Hour
+
+// sendCounter 当天发送成功的消息数，按本地时区零点重置
+type sendCounter struct {
+	mu    sync.Mutex
+	day   string
+	count int
+}
+
+// SendsToday 返回本地时区当天通过 Send 发送成功的消息数。
+// 企业微信未提供查询每日剩余发送额度的接口，该计数仅统计当前客户端，可用于在接近额度上限时丢弃低优先级消息。
+// 设置了 Store 时计数保存在 Store 中，由同一应用的多个进程共享，读取失败时返回当前客户端的计数。
+// 该计数仅用于统计，不会限制发送，限制发送速率见 SetRateLimit
+func (n *Notify) SendsToday() int {
+	day := n.now().Format("2006-01-02")
+	if n.store != nil {
+		if count, err := n.loadSendCount(day); err == nil {
+			return count
+		}
+	}
+	n.sends.mu.Lock()
+	defer n.sends.mu.Unlock()
+	if n.sends.day != day {
+		return 0
+	}
+	return n.sends.count
+}
+
+// countSend 记录一次发送成功
+func (n *Notify) countSend() {
+	day := n.now().Format("2006-01-02")
+	n.sends.mu.Lock()
+	if n.sends.day != day {
+		n.sends.day = day
+		n.sends.count = 0
+	}
+	n.sends.count++
+	n.sends.mu.Unlock()
+
+	if n.store != nil {
+		n.incrSendCount(day)
+	}
+}
+
+// loadSendCount 从 Store 读取 day 的发送计数，不存在时返回 0
+func (n *Notify) loadSendCount(day string) (int, error) {
+	b, err := n.store.Get(n.storeKey("sends:" + day))
+	if errors.Is(err, ErrStoreNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(b))
+}
+
+// incrSendCount 将 Store 中 day 的发送计数加一。Store 不支持原子递增，多个进程同时发送时计数可能偏少
+func (n *Notify) incrSendCount(day string) {
+	count, err := n.loadSendCount(day)
+	if err != nil {
+		return
+	}
+	_ = n.store.Set(n.storeKey("sends:"+day), []byte(strconv.Itoa(count+1)), sendCountTTL)
+}
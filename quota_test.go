@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNotify_SendsToday(t *testing.T) {
+	current := time.Date(2022, 7, 9, 23, 59, 0, 0, time.Local)
+	n := New("corp", 1, "secret")
+	n.now = func() time.Time { return current }
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			if msgBody["text"].(Text).Content == "fail" {
+				return MessageResult{ErrorCode: 81013}, nil
+			}
+			return MessageResult{ErrorMsg: "ok"}, nil
+		}
+	})
+	receiver := MessageReceiver{ToUser: "@all"}
+
+	for _, content := range []string{"a", "b", "fail"} {
+		_, _ = n.Send(receiver, Text{Content: content}, nil)
+	}
+	if got := n.SendsToday(); got != 2 {
+		t.Errorf("SendsToday() = %d, want 2", got)
+	}
+
+	current = current.Add(2 * time.Minute)
+	if got := n.SendsToday(); got != 0 {
+		t.Errorf("SendsToday() after midnight = %d, want 0", got)
+	}
+	_, _ = n.Send(receiver, Text{Content: "c"}, nil)
+	if got := n.SendsToday(); got != 1 {
+		t.Errorf("SendsToday() = %d, want 1", got)
+	}
+}
+
+func TestNotify_SendsTodayStore(t *testing.T) {
+	store := NewMemoryStore()
+	newNotify := func() *Notify {
+		n := New("corp", 1, "secret")
+		n.SetStore(store)
+		n.Use(func(next SendFunc) SendFunc {
+			return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+				return MessageResult{ErrorMsg: "ok"}, nil
+			}
+		})
+		return n
+	}
+
+	receiver := MessageReceiver{ToUser: "@all"}
+	first, second := newNotify(), newNotify()
+	for i := 0; i < 2; i++ {
+		_, _ = first.Send(receiver, Text{Content: "hi"}, nil)
+	}
+	_, _ = second.Send(receiver, Text{Content: "hi"}, nil)
+	if got := second.SendsToday(); got != 3 {
+		t.Errorf("SendsToday() = %d, want 3", got)
+	}
+}
@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 等待客户端限流的令牌时 ctx 结束，本次未发送
+var ErrRateLimited = errors.New("send rate limit exceeded")
+
+// rateLimiterStateTTL 限流状态在 Store 中的有效期
+const rateLimiterStateTTL = 24 * time.Hour
+
+// rateLimiter 令牌桶，每秒补充 rate 个令牌，最多积累 burst 个。令牌不足时预占令牌并等待，令牌数可为负
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数，不大于 0 时不限流
+	burst  int
+	tokens float64
+	last   time.Time // 最近一次补充令牌的时间
+}
+
+// SetRateLimit 开启客户端限流，每分钟最多发送 perMinute 条消息，允许 burst 条的突发，burst 小于 1 时为 1。
+// 超过速率的发送等待令牌后再发送，等待期间 ctx 结束（含 TTL 超时）时返回 ErrRateLimited，perMinute 不大于 0 时关闭限流。
+// MessageOptions.Bypass 为 true 的发送不受限流限制且不占用令牌。
+// 设置了 Store 时令牌桶状态保存在 Store 中，同一应用的多个进程（如定时任务、serverless 函数）共享限流，
+// Store 不支持原子写入，多个进程同时发送时实际速率可能略高
+func (n *Notify) SetRateLimit(perMinute, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+	n.limiter.mu.Lock()
+	defer n.limiter.mu.Unlock()
+	n.limiter.rate = float64(perMinute) / 60
+	n.limiter.burst = burst
+	n.limiter.tokens = float64(burst)
+	n.limiter.last = time.Time{}
+}
+
+// reserve 预占一个令牌，返回需要等待的时长，未开启限流时 ok 为 false
+func (l *rateLimiter) reserve(now time.Time) (wait time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return 0, false
+	}
+	l.advance(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0, true
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second)), true
+}
+
+// cancel 归还预占的令牌
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tokens++; l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+}
+
+// advance 按距上次补充的时长补充令牌，调用方需持有 mu
+func (l *rateLimiter) advance(now time.Time) {
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	if now.After(l.last) {
+		l.last = now
+	}
+}
+
+// rateLimiterState 保存在 Store 中的令牌桶状态，供多个短生命周期的进程共享
+type rateLimiterState struct {
+	Tokens float64 `json:"tokens"`
+	Last   int64   `json:"last"` // 最近一次补充令牌的时间，Unix 纳秒
+}
+
+// rateLimitWait 等待限流的令牌，设置了 Store 时先从 Store 加载其他进程记录的令牌桶状态，预占后写回
+func (n *Notify) rateLimitWait(ctx context.Context) error {
+	if n.store != nil {
+		n.loadLimiterState()
+	}
+	wait, ok := n.limiter.reserve(n.now())
+	if !ok {
+		return nil
+	}
+	if n.store != nil {
+		n.saveLimiterState()
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		n.limiter.cancel()
+		if n.store != nil {
+			n.saveLimiterState()
+		}
+		return fmt.Errorf("%w: %v", ErrRateLimited, ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// loadLimiterState 从 Store 加载令牌桶状态，读取失败时使用内存中的状态
+func (n *Notify) loadLimiterState() {
+	b, err := n.store.Get(n.storeKey("ratelimit"))
+	if err != nil {
+		return
+	}
+	var state rateLimiterState
+	if err = n.codec.Unmarshal(b, &state); err != nil {
+		return
+	}
+	n.limiter.mu.Lock()
+	n.limiter.tokens = state.Tokens
+	n.limiter.last = time.Unix(0, state.Last)
+	n.limiter.mu.Unlock()
+}
+
+// saveLimiterState 将令牌桶状态写入 Store，未开启限流时不写入
+func (n *Notify) saveLimiterState() {
+	n.limiter.mu.Lock()
+	if n.limiter.rate <= 0 {
+		n.limiter.mu.Unlock()
+		return
+	}
+	state := rateLimiterState{Tokens: n.limiter.tokens, Last: n.limiter.last.UnixNano()}
+	n.limiter.mu.Unlock()
+
+	if b, err := n.codec.Marshal(state); err == nil {
+		_ = n.store.Set(n.storeKey("ratelimit"), b, rateLimiterStateTTL)
+	}
+}
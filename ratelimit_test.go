@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_SetRateLimit(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	now := time.Date(2022, 7, 9, 10, 0, 0, 0, time.Local)
+	n.now = func() time.Time { return now }
+	n.SetRateLimit(60, 2)
+	receiver := MessageReceiver{ToUser: "@all"}
+	options := &MessageOptions{TTL: 20 * time.Millisecond}
+
+	for i := 0; i < 2; i++ {
+		if _, err := n.Send(receiver, Text{Content: "hi"}, options); err != nil {
+			t.Fatalf("Send() error = %v, want burst allowed", err)
+		}
+	}
+	if _, err := n.Send(receiver, Text{Content: "hi"}, options); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Send() error = %v, want %v", err, ErrRateLimited)
+	}
+
+	// 补充令牌后继续发送
+	now = now.Add(time.Second)
+	if _, err := n.Send(receiver, Text{Content: "hi"}, options); err != nil {
+		t.Errorf("Send() error = %v, want sent after refill", err)
+	}
+	if got := atomic.LoadInt32(&sends); got != 3 {
+		t.Errorf("sends = %d, want 3", got)
+	}
+}
+
+func TestNotify_SetRateLimitStore(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}
+	now := time.Date(2022, 7, 9, 10, 0, 0, 0, time.Local)
+	store := NewMemoryStore()
+	receiver := MessageReceiver{ToUser: "@all"}
+	options := &MessageOptions{TTL: 20 * time.Millisecond}
+
+	// 短生命周期的进程各自创建客户端，通过 Store 共享令牌桶
+	first := newTestNotify(t, handler)
+	first.now = func() time.Time { return now }
+	first.SetStore(store)
+	first.SetRateLimit(60, 1)
+	if _, err := first.Send(receiver, Text{Content: "hi"}, options); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+
+	second := newTestNotify(t, handler)
+	second.now = func() time.Time { return now }
+	second.SetStore(store)
+	second.SetRateLimit(60, 1)
+	if _, err := second.Send(receiver, Text{Content: "hi"}, options); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Send() error = %v, want %v shared through store", err, ErrRateLimited)
+	}
+}
+
+func TestNotify_SetRateLimitBypass(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	now := time.Date(2022, 7, 9, 10, 0, 0, 0, time.Local)
+	n.now = func() time.Time { return now }
+	n.SetRateLimit(1, 1)
+	receiver := MessageReceiver{ToUser: "@all"}
+	if _, err := n.Send(receiver, Text{Content: "routine"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+
+	// 紧急告警跳过限流，不在低优先级消息之后排队
+	if _, err := n.Send(receiver, Text{Content: "page"}, &MessageOptions{Bypass: true}); err != nil {
+		t.Errorf("Send() error = %v, want bypass of rate limit", err)
+	}
+	ctx := WithOptions(context.Background(), RequestOptions{Bypass: true})
+	if _, err := n.SendContext(ctx, receiver, Text{Content: "page"}, nil); err != nil {
+		t.Errorf("SendContext() error = %v, want bypass of rate limit from ctx", err)
+	}
+	if _, err := n.Send(receiver, Text{Content: "routine"}, &MessageOptions{TTL: 20 * time.Millisecond}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Send() error = %v, want %v", err, ErrRateLimited)
+	}
+	if got := atomic.LoadInt32(&sends); got != 3 {
+		t.Errorf("sends = %d, want 3", got)
+	}
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// SendRawResponse 将 body 原样发送到 message/send 接口并返回原始响应，用于查看响应头等排查问题，调用方需关闭 Body。
+// body 需包含 agentid、msgtype 等全部字段，可由 Marshal 生成后解析得到。不经过拦截器、熔断及重试，不解析返回的错误码
+func (n *Notify) SendRawResponse(body map[string]interface{}) (*http.Response, error) {
+	if len(body) == 0 {
+		return nil, errors.New("message body can not be empty")
+	}
+	ctx := context.Background()
+	token, _, _, err := n.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return n.postMessage(ctx, token, body)
+}
+
+// RawMessage 原始消息，用于发送尚未支持的消息类型。MsgType 为 msgtype，
+// Content 为该类型的消息内容，可以是 json.RawMessage、map[string]interface{} 或其他可编码为 JSON 的值，发送时原样编码
+type RawMessage struct {
+	MsgType string
+	Content interface{}
+}
+
+func (r RawMessage) key() string {
+	return r.MsgType
+}
+
+// MarshalJSON 编码为 Content 本身
+func (r RawMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Content)
+}
+
+// validate 校验 msgtype 及消息内容不能为空
+func (r RawMessage) validate() error {
+	if r.MsgType == "" {
+		return errors.New("raw message type can not be empty")
+	}
+	if r.Content == nil {
+		return errors.New("raw message content can not be empty")
+	}
+	if b, ok := r.Content.(json.RawMessage); ok && !json.Valid(b) {
+		return errors.New("raw message content is not valid json")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ldLirn/notify/markdown"
+)
+
+func TestNotify_SendRawResponse(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Error-Code", "0")
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	res, err := n.SendRawResponse(map[string]interface{}{"touser": "@all", "agentid": 1, "msgtype": "text", "text": Text{Content: "hi"}})
+	if err != nil {
+		t.Fatalf("SendRawResponse() error = %v, want no error", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	b, _ := io.ReadAll(res.Body)
+	if res.Header.Get("Error-Code") != "0" || string(b) != `{"errcode":0,"errmsg":"ok"}` {
+		t.Errorf("SendRawResponse() header = %v, body = %s", res.Header, b)
+	}
+
+	if _, err = n.SendRawResponse(nil); err == nil {
+		t.Errorf("SendRawResponse() with empty body want error")
+	}
+}
+
+func TestMarshal_RawMessage(t *testing.T) {
+	receiver := MessageReceiver{ToUser: "zhangsan"}
+	got, err := Marshal(receiver, RawMessage{MsgType: "template_card", Content: json.RawMessage(`{"card_type":"text_notice"}`)}, nil, 1)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v, want no error", err)
+	}
+	want := `{"agentid":1,"msgtype":"template_card","template_card":{"card_type":"text_notice"},"toparty":"","totag":"","touser":"zhangsan"}`
+	if string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	for _, message := range []RawMessage{{Content: map[string]interface{}{}}, {MsgType: "template_card"}, {MsgType: "template_card", Content: json.RawMessage(`{`)}} {
+		if _, err := Marshal(receiver, message, nil, 1); err == nil {
+			t.Errorf("Marshal(%+v) want error", message)
+		}
+	}
+}
+
+func TestMarshal_UnrecognizedMessageType(t *testing.T) {
+	_, err := Marshal(MessageReceiver{ToUser: "zhangsan"}, struct{}{}, nil, 1)
+	if err == nil || err.Error() != "unrecognized message type: struct {}" {
+		t.Errorf("Marshal() error = %v, want unrecognized message type: struct {}", err)
+	}
+}
+
+func TestMarshal_RawMessageMarkdownTable(t *testing.T) {
+	table := markdown.Table([]string{"主机", "状态"}, [][]string{{"web-1", "宕机"}})
+	got, err := Marshal(MessageReceiver{ToUser: "zhangsan"}, RawMessage{MsgType: "markdown_v2", Content: map[string]string{"content": table}}, nil, 1)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v, want no error", err)
+	}
+	want := `{"agentid":1,"markdown_v2":{"content":"| 主机 | 状态 |\n| --- | --- |\n| web-1 | 宕机 |"},"msgtype":"markdown_v2","toparty":"","totag":"","touser":"zhangsan"}`
+	if string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNoValidReceiver 接收者规范化后没有任何有效的 id，如 ToUser 为 "||" 或仅包含空白
+var ErrNoValidReceiver = errors.New("message receiver contains no valid ids")
+
+// Normalize 返回规范化后的接收者，去除每个 id 两端的空白及 ‘|’ 之间的空项，如 " alice| |bob|" 规范化为 "alice|bob"
+func (r MessageReceiver) Normalize() MessageReceiver {
+	return MessageReceiver{
+		ToUser:  normalizeIDs(r.ToUser),
+		ToParty: normalizeIDs(r.ToParty),
+		ToTag:   normalizeIDs(r.ToTag),
+	}
+}
+
+// normalizeReceiver 规范化接收者，原本设置了接收者但规范化后为空时返回 ErrNoValidReceiver
+func normalizeReceiver(receiver MessageReceiver) (MessageReceiver, error) {
+	normalized := receiver.Normalize()
+	if normalized == (MessageReceiver{}) && receiver != (MessageReceiver{}) {
+		return normalized, ErrNoValidReceiver
+	}
+	return normalized, nil
+}
+
+// normalizeIDs 去除 ‘|’ 分隔的 id 列表中每项两端的空白及空项
+func normalizeIDs(ids string) string {
+	if ids == "" {
+		return ""
+	}
+	list := strings.Split(ids, "|")
+	valid := list[:0]
+	for _, id := range list {
+		if id = strings.TrimSpace(id); id != "" {
+			valid = append(valid, id)
+		}
+	}
+	return strings.Join(valid, "|")
+}
+
+// ErrUnknownAlias 接收者别名未通过 RegisterReceiver 注册
+var ErrUnknownAlias = errors.New("receiver alias not registered")
+
+// receiverAliases 接收者别名
+type receiverAliases struct {
+	mu    sync.RWMutex
+	items map[string]MessageReceiver
+}
+
+// RegisterReceiver 注册接收者别名，如 "sre-oncall"，重复注册时覆盖，receiver 为空时删除别名。
+// 别名在发送时解析，修改后的接收者对之后的发送生效
+func (n *Notify) RegisterReceiver(name string, receiver MessageReceiver) {
+	n.aliases.mu.Lock()
+	defer n.aliases.mu.Unlock()
+	if receiver == (MessageReceiver{}) {
+		delete(n.aliases.items, name)
+		return
+	}
+	if n.aliases.items == nil {
+		n.aliases.items = make(map[string]MessageReceiver)
+	}
+	n.aliases.items[name] = receiver
+}
+
+// ResolveAlias 返回别名对应的接收者，未注册时返回 ErrUnknownAlias
+func (n *Notify) ResolveAlias(name string) (MessageReceiver, error) {
+	n.aliases.mu.RLock()
+	defer n.aliases.mu.RUnlock()
+	receiver, ok := n.aliases.items[name]
+	if !ok {
+		return receiver, fmt.Errorf("%w: %s", ErrUnknownAlias, name)
+	}
+	return receiver, nil
+}
+
+// SendToAlias 发送消息给别名 name 对应的接收者
+func (n *Notify) SendToAlias(name string, message interface{}, options *MessageOptions) (MessageResult, error) {
+	receiver, err := n.ResolveAlias(name)
+	if err != nil {
+		return MessageResult{}, err
+	}
+	return n.Send(receiver, message, options)
+}
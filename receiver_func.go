@@ -0,0 +1,22 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ReceiverFunc 在发送前动态获取接收人，如从排班系统查询当前的值班人员
+type ReceiverFunc func(ctx context.Context) (MessageReceiver, error)
+
+// SendResolved 调用 resolve 获取接收人后发送消息，resolve 返回错误时不发送并返回该错误
+func (n *Notify) SendResolved(ctx context.Context, resolve ReceiverFunc, message interface{}, options *MessageOptions) (MessageResult, error) {
+	if resolve == nil {
+		return MessageResult{}, errors.New("receiver func can not be nil")
+	}
+	receiver, err := resolve(ctx)
+	if err != nil {
+		return MessageResult{}, fmt.Errorf("resolve receiver error: %w", err)
+	}
+	return n.SendContext(ctx, receiver, message, options)
+}
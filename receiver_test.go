@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMessageReceiver_Normalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		receiver MessageReceiver
+		want     MessageReceiver
+	}{
+		{name: "Clean", receiver: MessageReceiver{ToUser: "alice|bob", ToParty: "2"}, want: MessageReceiver{ToUser: "alice|bob", ToParty: "2"}},
+		{name: "Spaces", receiver: MessageReceiver{ToUser: " alice | bob ", ToTag: " 3"}, want: MessageReceiver{ToUser: "alice|bob", ToTag: "3"}},
+		{name: "EmptySegments", receiver: MessageReceiver{ToUser: "|alice||bob|", ToParty: "||"}, want: MessageReceiver{ToUser: "alice|bob"}},
+		{name: "All", receiver: MessageReceiver{ToUser: " @all "}, want: MessageReceiver{ToUser: "@all"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.receiver.Normalize(); got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshal_NoValidReceiver(t *testing.T) {
+	if _, err := Marshal(MessageReceiver{ToUser: "||", ToParty: " "}, Text{Content: "hi"}, nil, 1); !errors.Is(err, ErrNoValidReceiver) {
+		t.Errorf("Marshal() error = %v, want %v", err, ErrNoValidReceiver)
+	}
+
+	got, err := Marshal(MessageReceiver{ToUser: "alice||"}, Text{Content: "hi"}, nil, 1)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v, want no error", err)
+	}
+	receiver, _, _, _, err := Unmarshal(got)
+	if err != nil || receiver.ToUser != "alice" {
+		t.Errorf("Marshal() touser = %q, error = %v, want %q", receiver.ToUser, err, "alice")
+	}
+}
+
+func TestNotify_SendToAlias(t *testing.T) {
+	var sent string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		sent = string(b)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	n.RegisterReceiver("sre-oncall", MessageReceiver{ToUser: "alice"})
+	n.RegisterReceiver("sre-oncall", MessageReceiver{ToUser: "bob", ToTag: "3"})
+	if _, err := n.SendToAlias("sre-oncall", Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("SendToAlias() error = %v, want no error", err)
+	}
+	if !strings.Contains(sent, `"touser":"bob"`) || !strings.Contains(sent, `"totag":"3"`) {
+		t.Errorf("SendToAlias() body = %s, want latest registered receiver", sent)
+	}
+
+	n.RegisterReceiver("sre-oncall", MessageReceiver{})
+	if _, err := n.SendToAlias("sre-oncall", Text{Content: "hi"}, nil); !errors.Is(err, ErrUnknownAlias) {
+		t.Errorf("SendToAlias() error = %v, want %v", err, ErrUnknownAlias)
+	}
+}
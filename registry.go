@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Registry 管理多个企业的客户端，按 corpID 索引，所有客户端共享同一个 HTTP 客户端及 token 存储
+type Registry struct {
+	mu      sync.Mutex
+	clients map[string]*Notify
+	client  *http.Client
+	store   Store
+}
+
+// NewRegistry 创建客户端注册表，client 为 nil 时使用 10 秒超时的默认客户端，store 为 nil 时使用 MemoryStore
+func NewRegistry(client *http.Client, store Store) *Registry {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Registry{clients: make(map[string]*Notify), client: client, store: store}
+}
+
+// Register 创建并注册企业的客户端，corpID 已注册时替换原有客户端
+func (r *Registry) Register(corpID string, agentID int64, appSecret string) *Notify {
+	n := New(corpID, agentID, appSecret)
+	n.SetHTTPClient(r.client)
+	n.SetStore(r.store)
+
+	r.mu.Lock()
+	r.clients[corpID] = n
+	r.mu.Unlock()
+	return n
+}
+
+// For 返回 corpID 对应的客户端，未注册时返回 nil
+func (r *Registry) For(corpID string) *Notify {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clients[corpID]
+}
+
+// CorpIDs 返回已注册的全部 corpID，按字典序排列
+func (r *Registry) CorpIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.clients))
+	for id := range r.clients {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Close 移除全部客户端并关闭共享 HTTP 客户端的空闲连接
+func (r *Registry) Close() {
+	r.mu.Lock()
+	r.clients = make(map[string]*Notify)
+	r.mu.Unlock()
+	r.client.CloseIdleConnections()
+}
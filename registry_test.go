@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"` + r.URL.Query().Get("corpid") + `","expires_in":7200}`))
+	}))
+	defer server.Close()
+
+	r := NewRegistry(nil, nil)
+	defer r.Close()
+	for _, id := range []string{t.Name() + "b", t.Name() + "a"} {
+		r.Register(id, 1, "secret").baseURL = server.URL
+	}
+
+	if got, want := r.CorpIDs(), []string{t.Name() + "a", t.Name() + "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CorpIDs() = %v, want %v", got, want)
+	}
+	if r.For("missing") != nil {
+		t.Errorf("For(missing) want nil")
+	}
+
+	token, _, err := r.For(t.Name() + "a").GetToken()
+	if err != nil || token != t.Name()+"a" {
+		t.Fatalf("GetToken() = %v, %v, want %v", token, err, t.Name()+"a")
+	}
+
+	// 重新注册后从共享存储读取 token，不再请求接口
+	n := r.Register(t.Name()+"a", 1, "secret")
+	n.baseURL = server.URL
+	if token, _, err = n.GetToken(); err != nil || token != t.Name()+"a" {
+		t.Fatalf("GetToken() = %v, %v, want %v", token, err, t.Name()+"a")
+	}
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("gettoken requests = %d, want 1", got)
+	}
+
+	r.Close()
+	if len(r.CorpIDs()) != 0 {
+		t.Errorf("CorpIDs() after Close() = %v, want empty", r.CorpIDs())
+	}
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// requestOptionsKey ctx 中通过 WithOptions 设置的单次请求配置
+type requestOptionsKey struct{}
+
+// RequestOptions 单次请求配置，通过 WithOptions 附加在 ctx 中，覆盖客户端及消息的默认配置，零值字段不覆盖
+type RequestOptions struct {
+	Bypass       bool          // 跳过客户端限流及熔断直接发送，同 MessageOptions.Bypass
+	Priority     Priority      // 非 PriorityNormal 时覆盖消息优先级，紧急消息不受免打扰时段限制
+	Timeout      time.Duration // 大于 0 时覆盖 MessageOptions.TTL，包括重试在内的整个发送过程的时限
+	TokenRetries int           // token 失效时的重试次数，大于 0 时覆盖 SetTokenRetries，最大 3，小于 0 表示不重试
+}
+
+// WithOptions 返回附加了单次请求配置的 ctx，用于在中间件中按请求调整发送策略而无需修改调用参数，
+// 如将值班告警标记为紧急并跳过熔断。通过 SendContext 等接收 ctx 的方法发送时生效
+func WithOptions(ctx context.Context, opts RequestOptions) context.Context {
+	return context.WithValue(ctx, requestOptionsKey{}, opts)
+}
+
+// applyRequestOptions 将 ctx 中的单次请求配置合并到 options，不修改调用方传入的 options
+func applyRequestOptions(ctx context.Context, options *MessageOptions) *MessageOptions {
+	opts, ok := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	if !ok {
+		return options
+	}
+	var merged MessageOptions
+	if options != nil {
+		merged = *options
+	}
+	merged.Bypass = merged.Bypass || opts.Bypass
+	if opts.Priority != PriorityNormal {
+		merged.Priority = opts.Priority
+	}
+	if opts.Timeout > 0 {
+		merged.TTL = opts.Timeout
+	}
+	return &merged
+}
+
+// tokenRetriesFor 返回本次发送 token 失效时的重试次数
+func (n *Notify) tokenRetriesFor(ctx context.Context) int {
+	opts, ok := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	if !ok || opts.TokenRetries == 0 {
+		return n.tokenRetries
+	}
+	if opts.TokenRetries < 0 {
+		return 0
+	}
+	if opts.TokenRetries > maxTokenRetries {
+		return maxTokenRetries
+	}
+	return opts.TokenRetries
+}
@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithOptions(t *testing.T) {
+	var sends int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&sends, 1) <= 2 {
+			_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":42001,"errmsg":"access_token expired"}`))
+	})
+	n.EnableCircuitBreaker(2, time.Minute)
+	receiver := MessageReceiver{ToUser: "@all"}
+	for i := 0; i < 2; i++ {
+		_, _ = n.Send(receiver, Text{Content: "hi"}, nil)
+	}
+	if _, err := n.Send(receiver, Text{Content: "hi"}, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Send() error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	options := &MessageOptions{Safe: true}
+	ctx := WithOptions(context.Background(), RequestOptions{Bypass: true, TokenRetries: -1})
+	result, err := n.SendContext(ctx, receiver, Text{Content: "hi"}, options)
+	if err != nil || result.ErrorCode != 42001 {
+		t.Fatalf("SendContext() = %+v, %v, want expired token result without retry", result, err)
+	}
+	if got := atomic.LoadInt32(&sends); got != 3 {
+		t.Errorf("sends = %d, want 3", got)
+	}
+	if options.Bypass {
+		t.Errorf("SendContext() modified caller options = %+v", options)
+	}
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Reset 清空内存中的 access_token、标签、userid、成员激活状态及素材缓存、当天发送计数、熔断状态、限流令牌、故障队列及去重记录，保留回调、拦截器及其他配置，
+// 可在测试用例之间复用同一个客户端。removeCacheFile 为 true 时同时删除 token 缓存文件，共享存储 Store 中的数据不受影响
+func (n *Notify) Reset(removeCacheFile bool) error {
+	n.mu.Lock()
+	n.Token = ""
+	n.TokenExpiresAt = 0
+	n.mu.Unlock()
+
+	n.tags.mu.Lock()
+	n.tags.ids = nil
+	n.tags.mu.Unlock()
+
+	n.users.mu.Lock()
+	n.users.ids = nil
+	n.users.mu.Unlock()
+
+	n.active.mu.Lock()
+	n.active.items = nil
+	n.active.mu.Unlock()
+
+	n.media.mu.Lock()
+	n.media.items = nil
+	n.media.mu.Unlock()
+
+	n.sends.mu.Lock()
+	n.sends.day = ""
+	n.sends.count = 0
+	n.sends.mu.Unlock()
+
+	n.breaker.mu.Lock()
+	n.breaker.failures = 0
+	n.breaker.probing = false
+	n.breaker.mu.Unlock()
+
+	n.limiter.mu.Lock()
+	n.limiter.tokens = float64(n.limiter.burst)
+	n.limiter.last = time.Time{}
+	n.limiter.mu.Unlock()
+
+	n.outage.mu.Lock()
+	n.outage.items = nil
+	n.outage.mu.Unlock()
+
+	n.dedup.mu.Lock()
+	n.dedup.expires = nil
+	n.dedup.mu.Unlock()
+
+	if removeCacheFile && n.CacheFilePath != "" {
+		if err := os.Remove(n.CacheFilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove cache file error: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotify_Reset(t *testing.T) {
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":-1,"errmsg":"system busy"}`))
+	})
+	n.SetCacheFilePath(filepath.Join(t.TempDir(), "cache"))
+	n.EnableTokenPersist()
+	n.EnableCircuitBreaker(1, 0)
+
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	n.countSend()
+	if _, err := os.Stat(n.CacheFilePath); err != nil {
+		t.Fatalf("cache file error = %v, want saved", err)
+	}
+
+	if err := n.Reset(true); err != nil {
+		t.Fatalf("Reset() error = %v, want no error", err)
+	}
+	if n.Token != "" || n.TokenExpiresAt != 0 || n.SendsToday() != 0 || n.breaker.failures != 0 {
+		t.Errorf("Reset() left token = %q, sends = %d, failures = %d", n.Token, n.SendsToday(), n.breaker.failures)
+	}
+	if _, err := os.Stat(n.CacheFilePath); !os.IsNotExist(err) {
+		t.Errorf("cache file error = %v, want removed", err)
+	}
+	if err := n.Reset(true); err != nil {
+		t.Errorf("Reset() without cache file error = %v, want no error", err)
+	}
+}
+
+func TestNotify_ResetCaches(t *testing.T) {
+	var lookups int
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","userid":"zhangsan"}`))
+	})
+	n.EnableOutageQueue(10)
+	if _, err := n.ResolveUserIDByEmail("zhangsan@example.com"); err != nil {
+		t.Fatalf("ResolveUserIDByEmail() error = %v", err)
+	}
+	if !n.enqueueOutage(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil) {
+		t.Fatalf("enqueueOutage() = false, want queued")
+	}
+
+	if err := n.Reset(false); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if len(n.outage.items) != 0 {
+		t.Errorf("Reset() left %d queued messages", len(n.outage.items))
+	}
+	if _, err := n.ResolveUserIDByEmail("zhangsan@example.com"); err != nil || lookups != 2 {
+		t.Errorf("ResolveUserIDByEmail() lookups = %d, %v, want cache cleared by Reset", lookups, err)
+	}
+}
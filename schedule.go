@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SendAt 在时间 t 通过内部定时器发送消息，返回的 cancel 可取消尚未开始的发送，多次调用及并发调用均安全。
+// 定时发送仅保存在内存中，进程重启后未发送的消息将丢失，如需可靠投递请使用外部调度并自行持久化。
+// 定时发送的结果可通过 Use 注册的拦截器获取
+func (n *Notify) SendAt(t time.Time, receiver MessageReceiver, message interface{}, options *MessageOptions) (cancel func(), err error) {
+	if message == nil {
+		return nil, ErrNilMessage
+	}
+	if len(receiver.ToUser) == 0 && len(receiver.ToParty) == 0 && len(receiver.ToTag) == 0 {
+		return nil, ErrNoReceiver
+	}
+	if _, ok := message.(MessageKey); !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedMessageType, message)
+	}
+
+	return n.schedule(t, func() {
+		_, _ = n.Send(receiver, message, options)
+	}), nil
+}
+
+// SendAtFunc 同 SendAt，接收人在发送时调用 resolve 获取，如定时发送给届时的值班人员。resolve 返回错误时不发送
+func (n *Notify) SendAtFunc(t time.Time, resolve ReceiverFunc, message interface{}, options *MessageOptions) (cancel func(), err error) {
+	if resolve == nil {
+		return nil, errors.New("receiver func can not be nil")
+	}
+	if message == nil {
+		return nil, ErrNilMessage
+	}
+	if _, ok := message.(MessageKey); !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedMessageType, message)
+	}
+
+	return n.schedule(t, func() {
+		_, _ = n.SendResolved(context.Background(), resolve, message, options)
+	}), nil
+}
+
+// schedule 在时间 t 执行 send，返回的 cancel 可取消尚未开始的执行
+func (n *Notify) schedule(t time.Time, send func()) (cancel func()) {
+	var mu sync.Mutex
+	var canceled bool
+	timer := time.AfterFunc(t.Sub(n.now()), func() {
+		mu.Lock()
+		if canceled {
+			mu.Unlock()
+			return
+		}
+		// 开始发送后不再允许取消
+		canceled = true
+		mu.Unlock()
+		send()
+	})
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !canceled {
+			canceled = true
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotify_SendAt(t *testing.T) {
+	n := New("corp", 1, "secret")
+	sent := make(chan string, 2)
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			sent <- msgBody["text"].(Text).Content
+			return MessageResult{ErrorMsg: "ok"}, nil
+		}
+	})
+	receiver := MessageReceiver{ToUser: "@all"}
+
+	canceled, err := n.SendAt(time.Now().Add(20*time.Millisecond), receiver, Text{Content: "canceled"}, nil)
+	if err != nil {
+		t.Fatalf("SendAt() error = %v, want no error", err)
+	}
+	canceled()
+	canceled()
+
+	if _, err := n.SendAt(time.Now().Add(20*time.Millisecond), receiver, Text{Content: "fired"}, nil); err != nil {
+		t.Fatalf("SendAt() error = %v, want no error", err)
+	}
+
+	select {
+	case got := <-sent:
+		if got != "fired" {
+			t.Errorf("SendAt() sent %v, want fired", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendAt() scheduled message not sent")
+	}
+	select {
+	case got := <-sent:
+		t.Errorf("SendAt() sent canceled message %v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := n.SendAt(time.Now(), MessageReceiver{}, Text{Content: "x"}, nil); err == nil {
+		t.Errorf("SendAt() without receiver want error")
+	}
+}
+
+func TestNotify_SendAtFunc(t *testing.T) {
+	n := New("corp", 1, "secret")
+	sent := make(chan string, 1)
+	n.Use(func(next SendFunc) SendFunc {
+		return func(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+			sent <- msgBody["touser"].(string)
+			return MessageResult{ErrorMsg: "ok"}, nil
+		}
+	})
+	var oncall atomic.Value
+	oncall.Store("alice")
+	resolve := func(ctx context.Context) (MessageReceiver, error) {
+		return MessageReceiver{ToUser: oncall.Load().(string)}, nil
+	}
+
+	if _, err := n.SendAtFunc(time.Now().Add(20*time.Millisecond), resolve, Text{Content: "page"}, nil); err != nil {
+		t.Fatalf("SendAtFunc() error = %v, want no error", err)
+	}
+	// 接收人在发送时获取
+	oncall.Store("bob")
+
+	select {
+	case got := <-sent:
+		if got != "bob" {
+			t.Errorf("SendAtFunc() sent to %v, want bob", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendAtFunc() scheduled message not sent")
+	}
+
+	errRotation := errors.New("rotation unavailable")
+	_, err := n.SendResolved(context.Background(), func(ctx context.Context) (MessageReceiver, error) {
+		return MessageReceiver{}, errRotation
+	}, Text{Content: "page"}, nil)
+	if !errors.Is(err, errRotation) {
+		t.Errorf("SendResolved() error = %v, want %v", err, errRotation)
+	}
+}
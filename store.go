@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrStoreNotFound Store 中不存在指定的 key 或已过期
+var ErrStoreNotFound = errors.New("store: key not found")
+
+// Store 键值存储，用于在多个客户端或进程间共享 access_token 等状态，可基于 Redis 等实现
+type Store interface {
+	// Get 获取 key 对应的值，不存在或已过期时返回 ErrStoreNotFound
+	Get(key string) ([]byte, error)
+	// Set 设置 key 对应的值，ttl 为 0 表示不过期
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete 删除 key，key 不存在时不返回错误
+	Delete(key string) error
+}
+
+// MemoryStore 基于内存的 Store，可在同一进程的多个客户端间共享
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]memoryItem)}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		delete(s.items, key)
+		return nil, ErrStoreNotFound
+	}
+	return item.value, nil
+}
+
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	s.mu.Lock()
+	s.items[key] = item
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// SetStore 设置共享存储，设置后 access_token 从 store 读取及写入，不再使用缓存文件。
+// 当天发送计数、熔断状态、限流令牌桶、故障队列、去重记录及按内容去重的 media_id 同样保存在 store 中，由同一应用的多个进程共享
+func (n *Notify) SetStore(store Store) {
+	n.store = store
+}
+
+// storeKey 返回当前应用在 store 中的 key
+func (n *Notify) storeKey(kind string) string {
+	return fmt.Sprintf("notify:%s:%s:%d", kind, n.corpID, n.agentID)
+}
+
+// loadStoreToken 从 store 读取未过期的 token
+func (n *Notify) loadStoreToken() (tokenCache, error) {
+	var cache tokenCache
+	b, err := n.store.Get(n.storeKey("token"))
+	if err != nil {
+		return cache, err
+	}
+	if err = n.codec.Unmarshal(b, &cache); err != nil {
+		return cache, fmt.Errorf("unmarshal store token error: %w", err)
+	}
+	if n.now().Unix() >= cache.TokenExpiresAt {
+		return cache, ErrStoreNotFound
+	}
+	return cache, nil
+}
+
+// saveStoreToken 将 token 写入 store，有效期与 token 一致
+func (n *Notify) saveStoreToken(cache tokenCache) error {
+	b, err := n.codec.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("marshal store token error: %w", err)
+	}
+	ttl := time.Duration(cache.TokenExpiresAt-n.now().Unix()) * time.Second
+	return n.store.Set(n.storeKey("token"), b, ttl)
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// brokenStore 读写均失败的 Store
+type brokenStore struct{}
+
+func (brokenStore) Get(string) ([]byte, error)              { return nil, errors.New("connection refused") }
+func (brokenStore) Set(string, []byte, time.Duration) error { return errors.New("connection refused") }
+func (brokenStore) Delete(string) error                     { return errors.New("connection refused") }
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	if _, err := s.Get("k"); err != ErrStoreNotFound {
+		t.Errorf("Get() error = %v, want %v", err, ErrStoreNotFound)
+	}
+	_ = s.Set("k", []byte("v"), time.Hour)
+	if v, err := s.Get("k"); err != nil || string(v) != "v" {
+		t.Errorf("Get() = %s, %v, want v", v, err)
+	}
+	_ = s.Set("expired", []byte("v"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, err := s.Get("expired"); err != ErrStoreNotFound {
+		t.Errorf("Get() expired error = %v, want %v", err, ErrStoreNotFound)
+	}
+	_ = s.Delete("k")
+	if _, err := s.Get("k"); err != ErrStoreNotFound {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrStoreNotFound)
+	}
+}
+
+func TestNotify_StalenessGrace(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","access_token":"fresh","expires_in":7200}`))
+	}))
+	defer server.Close()
+
+	now := time.Unix(1600000000, 0)
+	n := New(t.Name(), 1, "secret")
+	n.baseURL = server.URL
+	n.now = func() time.Time { return now }
+	n.SetStore(brokenStore{})
+	n.Token, n.TokenExpiresAt = "stale", now.Unix()-10
+	n.StalenessGrace = time.Minute
+
+	if token, _, err := n.GetToken(); err != nil || token != "stale" {
+		t.Errorf("GetToken() = %v, %v, want stale token within grace", token, err)
+	}
+	now = now.Add(time.Minute)
+	if token, _, err := n.GetToken(); err != nil || token != "fresh" {
+		t.Errorf("GetToken() = %v, %v, want refreshed token after grace", token, err)
+	}
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("gettoken requests = %d, want 1", got)
+	}
+}
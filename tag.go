@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Tag 通讯录标签
+type Tag struct {
+	TagID   int64  `json:"tagid"`   // 标签id
+	TagName string `json:"tagname"` // 标签名
+}
+
+// tagCache 标签名到标签id的缓存
+type tagCache struct {
+	mu  sync.Mutex
+	ids map[string]int64
+}
+
+// ListTags 获取标签列表，同时刷新标签名缓存
+func (n *Notify) ListTags() ([]Tag, error) {
+	var result struct {
+		TagList []Tag `json:"taglist"`
+	}
+	if err := n.callAPI(http.MethodGet, "tag/list", nil, nil, &result); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int64, len(result.TagList))
+	for _, t := range result.TagList {
+		ids[t.TagName] = t.TagID
+	}
+	n.tags.mu.Lock()
+	n.tags.ids = ids
+	n.tags.mu.Unlock()
+
+	return result.TagList, nil
+}
+
+// ResolveTagByName 根据标签名查询标签id，优先使用缓存，缓存未命中时重新拉取标签列表
+func (n *Notify) ResolveTagByName(name string) (int64, error) {
+	n.tags.mu.Lock()
+	id, ok := n.tags.ids[name]
+	n.tags.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	tags, err := n.ListTags()
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range tags {
+		if t.TagName == name {
+			return t.TagID, nil
+		}
+	}
+	return 0, fmt.Errorf("tag not found: %s", name)
+}
+
+// TagReceiver 根据标签名构造消息接收者
+func (n *Notify) TagReceiver(names ...string) (MessageReceiver, error) {
+	var receiver MessageReceiver
+	if len(names) == 0 {
+		return receiver, fmt.Errorf("tag names can not be empty")
+	}
+
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		id, err := n.ResolveTagByName(name)
+		if err != nil {
+			return receiver, err
+		}
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	receiver.ToTag = strings.Join(ids, "|")
+	return receiver, nil
+}
@@ -0,0 +1,20 @@
+package notify
+
+import "testing"
+
+func TestNotify_TagReceiver(t *testing.T) {
+	n := New("corp", 1, "secret")
+	n.tags.ids = map[string]int64{"oncall": 1, "sre": 12}
+
+	got, err := n.TagReceiver("oncall", "sre")
+	if err != nil {
+		t.Fatalf("TagReceiver() error = %v, want no error", err)
+	}
+	if got.ToTag != "1|12" {
+		t.Errorf("TagReceiver() ToTag = %v, want %v", got.ToTag, "1|12")
+	}
+
+	if _, err := n.TagReceiver(); err == nil {
+		t.Errorf("TagReceiver() with no names want error")
+	}
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// taskCardKeyPattern 任务id及按钮key只能由数字、字母和“_-@.”组成，最长128字节
+var taskCardKeyPattern = regexp.MustCompile(`^[0-9A-Za-z_\-@.]{1,128}$`)
+
+// Validate 校验任务id、按钮个数为1~2个、按钮key的字符及唯一性、按钮颜色为 red 或 blue
+func (t TaskCard) Validate() error {
+	if t.Title == "" {
+		return errors.New("taskcard title can not be empty")
+	}
+	if !taskCardKeyPattern.MatchString(t.TaskID) {
+		return fmt.Errorf("taskcard task id %q must be 1-128 characters of [0-9A-Za-z_-@.]", t.TaskID)
+	}
+	if len(t.Buttons) < 1 || len(t.Buttons) > 2 {
+		return fmt.Errorf("taskcard must have 1 or 2 buttons, got %d", len(t.Buttons))
+	}
+	keys := make(map[string]bool, len(t.Buttons))
+	for i, b := range t.Buttons {
+		if !taskCardKeyPattern.MatchString(b.Key) {
+			return fmt.Errorf("taskcard button %d key %q must be 1-128 characters of [0-9A-Za-z_-@.]", i, b.Key)
+		}
+		if keys[b.Key] {
+			return fmt.Errorf("taskcard button %d key %q is duplicated", i, b.Key)
+		}
+		keys[b.Key] = true
+		if b.Name == "" {
+			return fmt.Errorf("taskcard button %d name can not be empty", i)
+		}
+		if b.Color != "" && b.Color != "red" && b.Color != "blue" {
+			return fmt.Errorf("taskcard button %d color %q must be red or blue", i, b.Color)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskCard_Validate(t *testing.T) {
+	valid := func() TaskCard {
+		return TaskCard{Title: "t", TaskID: "task_1", Buttons: []TaskCardButton{{Key: "k1", Name: "批准", Color: "red"}, {Key: "k2", Name: "驳回"}}}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want no error", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(t *TaskCard)
+		want   string
+	}{
+		{"no buttons", func(t *TaskCard) { t.Buttons = nil }, "1 or 2 buttons"},
+		{"three buttons", func(t *TaskCard) { t.Buttons = append(t.Buttons, TaskCardButton{Key: "k3", Name: "n"}) }, "1 or 2 buttons"},
+		{"duplicate key", func(t *TaskCard) { t.Buttons[1].Key = "k1" }, "button 1 key \"k1\" is duplicated"},
+		{"bad key", func(t *TaskCard) { t.Buttons[0].Key = "k 1" }, "button 0 key"},
+		{"bad color", func(t *TaskCard) { t.Buttons[1].Color = "green" }, "button 1 color"},
+		{"bad task id", func(t *TaskCard) { t.TaskID = "" }, "task id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := valid()
+			tt.modify(&card)
+			if err := card.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.want)
+			}
+			if _, err := buildMessageBody(MessageReceiver{ToUser: "@all"}, card, nil, 1); err == nil {
+				t.Errorf("buildMessageBody() want validation error")
+			}
+		})
+	}
+}
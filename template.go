@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// Template 消息模板，消息中的字符串字段（如 content、title、description）可包含 {{.Field}} 等 text/template 占位符
+type Template struct {
+	message   interface{}
+	templates map[string]*template.Template // 字段原文到已解析模板的映射
+}
+
+// NewTemplate 解析 message 中全部字符串字段的占位符并创建模板，message 为 Text、TextCard 等消息类型的值
+func NewTemplate(message interface{}) (*Template, error) {
+	if _, ok := message.(MessageKey); !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedMessageType, message)
+	}
+	t := &Template{message: message, templates: make(map[string]*template.Template)}
+	var err error
+	walkStrings(reflect.ValueOf(message), func(s string) string {
+		if err != nil || !strings.Contains(s, "{{") {
+			return s
+		}
+		if _, ok := t.templates[s]; ok {
+			return s
+		}
+		var tmpl *template.Template
+		if tmpl, err = template.New("").Option("missingkey=error").Parse(s); err != nil {
+			err = fmt.Errorf("parse template %q error: %w", s, err)
+			return s
+		}
+		t.templates[s] = tmpl
+		return s
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render 使用 data 填充占位符并返回新的消息，模板本身不会被修改。填充后的消息会校验必填字段及长度限制
+func (t *Template) Render(data interface{}) (interface{}, error) {
+	v := reflect.New(reflect.TypeOf(t.message)).Elem()
+	v.Set(reflect.ValueOf(t.message))
+
+	var err error
+	walkStrings(v, func(s string) string {
+		tmpl, ok := t.templates[s]
+		if err != nil || !ok {
+			return s
+		}
+		var b strings.Builder
+		if err = tmpl.Execute(&b, data); err != nil {
+			err = fmt.Errorf("render template %q error: %w", s, err)
+			return s
+		}
+		return b.String()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := v.Interface()
+	if validator, ok := message.(interface{ Validate() error }); ok {
+		if err = validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if warnings := TruncationWarnings(message); len(warnings) > 0 {
+		return nil, errors.New(strings.Join(warnings, "; "))
+	}
+	return message, nil
+}
+
+// walkStrings 遍历 v 中可设置的字符串字段并替换为 fn 的返回值，遍历前复制切片以免修改原值
+func walkStrings(v reflect.Value, fn func(s string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(fn(v.String()))
+		} else {
+			fn(v.String())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			walkStrings(v.Field(i), fn)
+		}
+	case reflect.Slice:
+		if v.CanSet() && !v.IsNil() {
+			s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			reflect.Copy(s, v)
+			v.Set(s)
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), fn)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl, err := NewTemplate(News{Articles: []NewsArticle{{Title: "{{.Host}} is down", Description: "since {{.Since}}", URL: "https://example.com/{{.Host}}"}}})
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v, want no error", err)
+	}
+
+	got, err := tmpl.Render(map[string]string{"Host": "db1", "Since": "10:00"})
+	if err != nil {
+		t.Fatalf("Render() error = %v, want no error", err)
+	}
+	want := News{Articles: []NewsArticle{{Title: "db1 is down", Description: "since 10:00", URL: "https://example.com/db1"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Render() = %+v, want %+v", got, want)
+	}
+
+	// 模板不会被渲染结果修改
+	if got, _ = tmpl.Render(map[string]string{"Host": "db2", "Since": "11:00"}); got.(News).Articles[0].Title != "db2 is down" {
+		t.Errorf("Render() title = %v, want db2 is down", got.(News).Articles[0].Title)
+	}
+
+	if _, err = tmpl.Render(map[string]string{"Host": "db1"}); err == nil {
+		t.Errorf("Render() with missing key want error")
+	}
+	if _, err = tmpl.Render(map[string]string{"Host": strings.Repeat("a", maxTitleBytes), "Since": "10:00"}); err == nil {
+		t.Errorf("Render() with long title want error")
+	}
+}
+
+func TestNewTemplate(t *testing.T) {
+	if _, err := NewTemplate(Text{Content: "{{.Host"}); err == nil {
+		t.Errorf("NewTemplate() with bad placeholder want error")
+	}
+	if _, err := NewTemplate("text"); err == nil {
+		t.Errorf("NewTemplate() with unsupported type want error")
+	}
+
+	tmpl, err := NewTemplate(TextCard{Title: "{{.Host}}", URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v, want no error", err)
+	}
+	// 渲染后描述为空，不满足文本卡片必填字段
+	if _, err = tmpl.Render(map[string]string{"Host": "db1"}); err == nil {
+		t.Errorf("Render() with empty description want error")
+	}
+}
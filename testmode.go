@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// testModeMsg 测试模式下返回的 errmsg，便于在日志中与真实发送区分
+const testModeMsg = "ok (test mode)"
+
+// testMode 测试模式的记录目录及序号
+type testMode struct {
+	mu  sync.Mutex
+	dir string
+	seq int
+}
+
+// EnableTestMode 开启测试模式，Send 及 Upload 不再请求企业微信，也不获取 access_token，
+// 而是将请求内容写入 dir 下以时间及序号命名的文件并返回成功结果。返回结果的 errmsg 为 "ok (test mode)"，
+// msgid 及 media_id 以 "test-" 开头，SendInfo.TestMode 为 true，用于本地开发及事后检查。dir 不存在时自动创建
+func (n *Notify) EnableTestMode(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("test mode dir can not be empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create test mode dir error: %w", err)
+	}
+	n.test.mu.Lock()
+	n.test.dir = dir
+	n.test.mu.Unlock()
+	return nil
+}
+
+// testModeEnabled 是否开启了测试模式
+func (n *Notify) testModeEnabled() bool {
+	n.test.mu.Lock()
+	defer n.test.mu.Unlock()
+	return n.test.dir != ""
+}
+
+// testModeFile 创建测试模式的记录文件，返回文件及序号
+func (n *Notify) testModeFile(kind, name string) (*os.File, int, error) {
+	n.test.mu.Lock()
+	n.test.seq++
+	seq := n.test.seq
+	dir := n.test.dir
+	n.test.mu.Unlock()
+
+	filename := fmt.Sprintf("%s-%04d-%s%s", n.now().Format("20060102T150405.000"), seq, kind, name)
+	f, err := os.OpenFile(filepath.Join(dir, filename), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, 0, fmt.Errorf("create test mode file error: %w", err)
+	}
+	return f, seq, nil
+}
+
+// recordSend 将消息体写入记录文件并返回成功结果
+func (n *Notify) recordSend(ctx context.Context, msgBody map[string]interface{}) (MessageResult, error) {
+	sendInfoFrom(ctx).TestMode = true
+	b, err := n.codec.Marshal(msgBody)
+	if err != nil {
+		return MessageResult{}, fmt.Errorf("encode message error: %w", err)
+	}
+	f, seq, err := n.testModeFile("send", ".json")
+	if err != nil {
+		return MessageResult{}, err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err = f.Write(b); err != nil {
+		return MessageResult{}, fmt.Errorf("write test mode file error: %w", err)
+	}
+	return MessageResult{ErrorMsg: testModeMsg, MsgID: "test-" + strconv.Itoa(seq)}, nil
+}
+
+// recordUpload 将上传的文件内容写入记录文件并返回成功结果
+func (n *Notify) recordUpload(mediaType, filename string, r io.Reader) (UploadMediaResult, error) {
+	f, seq, err := n.testModeFile("upload-"+mediaType+"-", filepath.Base(filename))
+	if err != nil {
+		return UploadMediaResult{}, err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err = io.Copy(f, r); err != nil {
+		return UploadMediaResult{}, fmt.Errorf("write test mode file error: %w", err)
+	}
+	return UploadMediaResult{
+		ErrorMsg:  testModeMsg,
+		Type:      mediaType,
+		MediaID:   "test-media-" + strconv.Itoa(seq),
+		CreatedAt: strconv.FormatInt(n.now().Unix(), 10),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotify_EnableTestMode(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sends")
+	n := New(t.Name(), 1, "secret")
+	n.baseURL = "http://127.0.0.1:0"
+	var info SendInfo
+	n.OnSend(func(i SendInfo) { info = i })
+	if err := n.EnableTestMode(dir); err != nil {
+		t.Fatalf("EnableTestMode() error = %v, want no error", err)
+	}
+
+	result, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil)
+	if err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if result.ErrorMsg != testModeMsg || !strings.HasPrefix(result.MsgID, "test-") || !info.TestMode {
+		t.Errorf("Send() = %+v, TestMode = %v, want test mode result", result, info.TestMode)
+	}
+
+	path := filepath.Join(t.TempDir(), "a.png")
+	if err = os.WriteFile(path, []byte(pngHeader), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	media, err := n.Upload(UploadMedia{Type: "image", Path: path})
+	if err != nil || !strings.HasPrefix(media.MediaID, "test-media-") {
+		t.Errorf("Upload() = %+v, %v, want test media id", media, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("EnableTestMode() recorded %d files, want 2", len(entries))
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if !strings.Contains(string(b), `"content":"hi"`) {
+		t.Errorf("EnableTestMode() recorded %s, want message body", b)
+	}
+	b, _ = os.ReadFile(filepath.Join(dir, entries[1].Name()))
+	if string(b) != pngHeader {
+		t.Errorf("EnableTestMode() recorded upload %q, want file content", b)
+	}
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 消息字段的字节长度限制，超过时服务端会自动截断
+const (
+	maxTitleBytes       = 128
+	maxDescriptionBytes = 512
+)
+
+// NewTextCard 创建文本卡片消息，并校验字段长度
+func NewTextCard(title, description, url string) (TextCard, error) {
+	t := TextCard{Title: title, Description: description, URL: url}
+	return t, t.Validate()
+}
+
+// WithButton 设置按钮文字，不超过4个文字，超过时 Validate 返回错误
+func (t TextCard) WithButton(text string) TextCard {
+	t.BtnTxt = text
+	return t
+}
+
+// Validate 校验必填字段及字段长度
+func (t TextCard) Validate() error {
+	if t.Title == "" {
+		return errors.New("textcard title can not be empty")
+	}
+	if len(t.Title) > maxTitleBytes {
+		return fmt.Errorf("textcard title exceeds %d bytes", maxTitleBytes)
+	}
+	if t.Description == "" {
+		return errors.New("textcard description can not be empty")
+	}
+	if len(t.Description) > maxDescriptionBytes {
+		return fmt.Errorf("textcard description exceeds %d bytes", maxDescriptionBytes)
+	}
+	if t.URL == "" {
+		return errors.New("textcard url can not be empty")
+	}
+	if count := utf8.RuneCountInString(t.BtnTxt); count > maxBtnTxtRunes {
+		return fmt.Errorf("textcard btntxt is %d characters, exceeds %d", count, maxBtnTxtRunes)
+	}
+	return nil
+}
+
+var (
+	markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownFontPattern = regexp.MustCompile(`</?font[^>]*>`)
+	markdownLinePattern = regexp.MustCompile(`^\s*(#{1,6}\s+|>\s*|[-*]\s+)`)
+)
+
+// MarkdownToTextCard 将 markdown 消息转换为文本卡片，用于不能正常渲染 markdown 的客户端。
+// 第一个非空行为标题，其余行以 <br> 连接为描述，去除标题、引用、加粗、颜色等标记，链接保留文字，超长时按字节截断
+func MarkdownToTextCard(md Markdown, url string) TextCard {
+	var lines []string
+	for _, line := range strings.Split(md.Content, "\n") {
+		line = stripMarkdown(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	t := TextCard{URL: url}
+	if len(lines) > 0 {
+		t.Title = truncateBytes(lines[0], maxTitleBytes)
+		t.Description = truncateBytes(strings.Join(lines[1:], "<br>"), maxDescriptionBytes)
+	}
+	if t.Description == "" {
+		t.Description = t.Title
+	}
+	return t
+}
+
+// stripMarkdown 去除一行 markdown 的标题、引用、列表、加粗、代码及颜色标记，链接保留文字
+func stripMarkdown(line string) string {
+	line = markdownLinePattern.ReplaceAllString(line, "")
+	line = markdownLinkPattern.ReplaceAllString(line, "$1")
+	line = markdownFontPattern.ReplaceAllString(line, "")
+	return strings.TrimSpace(strings.NewReplacer("**", "", "`", "").Replace(line))
+}
+
+// MarkdownToText 将 markdown 消息转换为文本消息，逐行去除 markdown 标记
+func MarkdownToText(md Markdown) Text {
+	lines := strings.Split(md.Content, "\n")
+	for i, line := range lines {
+		lines[i] = stripMarkdown(line)
+	}
+	return Text{Content: strings.Join(lines, "\n")}
+}
+
+// truncateBytes 将 s 截断为不超过 limit 字节，不截断 UTF-8 字符
+func truncateBytes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewTextCard(t *testing.T) {
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		url         string
+		wantErr     bool
+	}{
+		{name: "Valid", title: "放假通知", description: "清明节放假通知", url: "https://work.weixin.qq.com/", wantErr: false},
+		{name: "EmptyTitle", title: "", description: "清明节放假通知", url: "https://work.weixin.qq.com/", wantErr: true},
+		{name: "LongTitle", title: strings.Repeat("a", 129), description: "清明节放假通知", url: "https://work.weixin.qq.com/", wantErr: true},
+		{name: "LongDescription", title: "放假通知", description: strings.Repeat("a", 513), url: "https://work.weixin.qq.com/", wantErr: true},
+		{name: "EmptyURL", title: "放假通知", description: "清明节放假通知", url: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTextCard(tt.title, tt.description, tt.url); (err != nil) != tt.wantErr {
+				t.Errorf("NewTextCard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	card, _ := NewTextCard("放假通知", "清明节放假通知", "https://work.weixin.qq.com/")
+	if got := card.WithButton("查看").BtnTxt; got != "查看" {
+		t.Errorf("WithButton() BtnTxt = %v, want %v", got, "查看")
+	}
+	if err := card.WithButton("查看详情").Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want 4 characters allowed", err)
+	}
+	if err := card.WithButton("查看详情内容").Validate(); err == nil {
+		t.Errorf("Validate() want error for btntxt over 4 characters")
+	}
+}
+
+func TestMarkdownToTextCard(t *testing.T) {
+	md := Markdown{Content: "# **DB down**\n\n> service: <font color=\"warning\">db1</font>\n- [runbook](https://example.com/runbook)"}
+	got := MarkdownToTextCard(md, "https://example.com")
+	want := TextCard{Title: "DB down", Description: "service: db1<br>runbook", URL: "https://example.com"}
+	if got != want {
+		t.Errorf("MarkdownToTextCard() = %+v, want %+v", got, want)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want no error", err)
+	}
+
+	long := MarkdownToTextCard(Markdown{Content: strings.Repeat("中", 100)}, "https://example.com")
+	if len(long.Title) > maxTitleBytes || !utf8.ValidString(long.Title) || long.Description != long.Title {
+		t.Errorf("MarkdownToTextCard() title = %q, description = %q", long.Title, long.Description)
+	}
+}
+
+func TestNotify_SetUnsupportedTypeFallback(t *testing.T) {
+	var sent []string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		sent = append(sent, string(b))
+		if strings.Contains(string(b), `"msgtype":"markdown"`) {
+			_, _ = w.Write([]byte(`{"errcode":40008,"errmsg":"invalid message type"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	n.Environment = "staging"
+	md := Markdown{Content: "**DB down**\n> [runbook](https://example.com)"}
+
+	if r, _ := n.Send(MessageReceiver{ToUser: "@all"}, md, nil); r.ErrorCode != 40008 || len(sent) != 1 {
+		t.Fatalf("Send() errcode = %d, sends = %d, want 40008 without fallback", r.ErrorCode, len(sent))
+	}
+
+	n.SetUnsupportedTypeFallback(true)
+	if r, err := n.Send(MessageReceiver{ToUser: "@all"}, md, nil); err != nil || r.ErrorCode != 0 {
+		t.Fatalf("Send() = %+v, %v, want success after fallback", r, err)
+	}
+	if want := `"text":{"content":"[STAGING] DB down\nrunbook"}`; !strings.Contains(sent[2], want) {
+		t.Errorf("fallback body = %s, want containing %s", sent[2], want)
+	}
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// tokenOverrideKey ctx 中由调用方提供的 access_token
+type tokenOverrideKey struct{}
+
+// errEmptyToken 调用方提供的 access_token 为空
+var errEmptyToken = errors.New("access token can not be empty")
+
+// SendWithToken 使用调用方提供的 access_token 发送消息，不获取、刷新或缓存 token，token 失效时也不重试，
+// 适用于由其他服务统一管理 token 的部署方式，此时 New 的 appSecret 可为空
+func (n *Notify) SendWithToken(token string, receiver MessageReceiver, message interface{}, options *MessageOptions) (MessageResult, error) {
+	if token == "" {
+		return MessageResult{}, errEmptyToken
+	}
+	return n.SendContext(context.WithValue(context.Background(), tokenOverrideKey{}, token), receiver, message, options)
+}
+
+// UploadWithToken 使用调用方提供的 access_token 上传临时素材，不获取、刷新或缓存 token
+func (n *Notify) UploadWithToken(token string, media UploadMedia) (UploadMediaResult, error) {
+	if token == "" {
+		return UploadMediaResult{}, errEmptyToken
+	}
+	return n.upload(token, media)
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotify_SendWithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/gettoken":
+			t.Errorf("gettoken requested, want supplied token used")
+		case r.URL.Query().Get("access_token") != "sidecar":
+			_, _ = w.Write([]byte(`{"errcode":40014,"errmsg":"invalid access_token"}`))
+		case r.URL.Path == "/media/upload":
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","type":"file","media_id":"m1"}`))
+		default:
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	}))
+	defer server.Close()
+	n := New(t.Name(), 1, "")
+	n.baseURL = server.URL
+
+	if r, err := n.SendWithToken("sidecar", MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil || r.ErrorCode != 0 {
+		t.Errorf("SendWithToken() = %+v, %v, want success", r, err)
+	}
+	if r, err := n.SendWithToken("stale", MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil || r.ErrorCode != 40014 {
+		t.Errorf("SendWithToken() = %+v, %v, want errcode 40014 without retry", r, err)
+	}
+	if _, err := n.SendWithToken("", MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err == nil {
+		t.Errorf("SendWithToken() with empty token want error")
+	}
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := n.UploadWithToken("sidecar", UploadMedia{Type: "file", Path: path}); err != nil || r.MediaID != "m1" {
+		t.Errorf("UploadWithToken() = %+v, %v, want media id m1", r, err)
+	}
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"strings"
+)
+
+// Tracer 链路追踪适配接口，可基于 OpenTelemetry 等实现，包本身不依赖具体的追踪库。
+// Start 应从 ctx 中获取上游的追踪上下文，并返回携带新 span 的 ctx
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span 一次操作的追踪记录
+type Span interface {
+	SetAttribute(key string, value interface{}) // 设置属性
+	AddEvent(name string)                       // 记录事件，如 token 失效重试
+	End(err error)                              // 结束 span，err 不为 nil 时记录错误
+}
+
+// SetTracer 设置链路追踪，每次 Send 及 access_token 刷新各生成一个 span，token 失效重试记录为事件
+func (n *Notify) SetTracer(tracer Tracer) {
+	n.tracer = tracer
+}
+
+type spanKey struct{}
+
+// startSpan 开始 span 并将其存入 ctx，未设置 Tracer 时返回不记录任何内容的 span
+func (n *Notify) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if n.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := n.tracer.Start(ctx, name)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// spanFrom 返回 ctx 中当前的 span
+func spanFrom(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// noopSpan 不记录任何内容的 span
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) AddEvent(string)                  {}
+func (noopSpan) End(error)                        {}
+
+// countIDs 返回‘|’分隔的 id 列表中的 id 个数
+func countIDs(ids string) int {
+	if ids == "" {
+		return 0
+	}
+	return strings.Count(ids, "|") + 1
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// recordTracer 记录 span 的 Tracer
+type recordTracer struct {
+	mu    sync.Mutex
+	spans []*recordSpan
+}
+
+type recordSpan struct {
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	events []string
+	ended  bool
+}
+
+type spanNameKey struct{}
+
+func (t *recordTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanNameKey{}).(string)
+	span := &recordSpan{name: name, parent: parent, attrs: make(map[string]interface{})}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanNameKey{}, name), span
+}
+
+func (s *recordSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordSpan) AddEvent(name string)                       { s.events = append(s.events, name) }
+func (s *recordSpan) End(error)                                  { s.ended = true }
+
+func TestNotify_SetTracer(t *testing.T) {
+	var calls int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			_, _ = w.Write([]byte(`{"errcode":42001,"errmsg":"access_token expired"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+	tracer := &recordTracer{}
+	n.SetTracer(tracer)
+
+	ctx := context.WithValue(context.Background(), spanNameKey{}, "incoming")
+	if _, err := n.SendContext(ctx, MessageReceiver{ToUser: "a|b", ToTag: "1"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("SendContext() error = %v, want no error", err)
+	}
+
+	var names []string
+	for _, s := range tracer.spans {
+		names = append(names, s.parent+">"+s.name)
+		if !s.ended {
+			t.Errorf("span %s not ended", s.name)
+		}
+	}
+	want := []string{"incoming>notify.send", "notify.send>notify.token_refresh", "notify.send>notify.token_refresh"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("spans = %v, want %v", names, want)
+	}
+	send := tracer.spans[0]
+	if send.attrs["notify.msgtype"] != "text" || send.attrs["notify.users"] != 2 || send.attrs["notify.tags"] != 1 || send.attrs["notify.errcode"] != int64(0) {
+		t.Errorf("send span attributes = %v", send.attrs)
+	}
+	if !reflect.DeepEqual(send.events, []string{"token_retry"}) {
+		t.Errorf("send span events = %v, want [token_retry]", send.events)
+	}
+}
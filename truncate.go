@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// maxTextBytes 文本消息内容的字节长度限制
+	maxTextBytes = 2048
+	// maxBtnTxtRunes 文本卡片按钮文字的字数限制
+	maxBtnTxtRunes = 4
+)
+
+// TruncationWarnings 返回 message 中超过文档长度限制、发送后会被服务端自动截断的字段，不修改 message。
+// 适用于希望记录警告但仍然发送的场景，比 Validate 宽松
+func TruncationWarnings(message interface{}) []string {
+	var warnings []string
+	checkBytes := func(field, value string, limit int) {
+		if len(value) > limit {
+			warnings = append(warnings, fmt.Sprintf("%s is %d bytes, exceeds %d and will be truncated", field, len(value), limit))
+		}
+	}
+
+	switch m := message.(type) {
+	case Text:
+		checkBytes("text.content", m.Content, maxTextBytes)
+	case Video:
+		checkBytes("video.title", m.Title, maxTitleBytes)
+		checkBytes("video.description", m.Description, maxDescriptionBytes)
+	case TextCard:
+		checkBytes("textcard.title", m.Title, maxTitleBytes)
+		checkBytes("textcard.description", m.Description, maxDescriptionBytes)
+		if count := utf8.RuneCountInString(m.BtnTxt); count > maxBtnTxtRunes {
+			warnings = append(warnings, fmt.Sprintf("textcard.btntxt is %d characters, exceeds %d and will be truncated", count, maxBtnTxtRunes))
+		}
+	case News:
+		for i, a := range m.Articles {
+			checkBytes(fmt.Sprintf("news.articles[%d].title", i), a.Title, maxTitleBytes)
+			checkBytes(fmt.Sprintf("news.articles[%d].description", i), a.Description, maxDescriptionBytes)
+		}
+	case MpNews:
+		for i, a := range m.Articles {
+			checkBytes(fmt.Sprintf("mpnews.articles[%d].title", i), a.Title, maxTitleBytes)
+			checkBytes(fmt.Sprintf("mpnews.articles[%d].digest", i), a.Digest, maxDescriptionBytes)
+		}
+	case TaskCard:
+		checkBytes("taskcard.title", m.Title, maxTitleBytes)
+		checkBytes("taskcard.description", m.Description, maxDescriptionBytes)
+	}
+	return warnings
+}
@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncationWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		message interface{}
+		want    int
+	}{
+		{"short text", Text{Content: "hi"}, 0},
+		{"long text", Text{Content: strings.Repeat("a", maxTextBytes+1)}, 1},
+		{"textcard", TextCard{Title: strings.Repeat("a", maxTitleBytes+1), Description: "d", BtnTxt: "查看详情内容"}, 2},
+		{"news", News{Articles: []NewsArticle{{Title: "t"}, {Title: "t", Description: strings.Repeat("a", maxDescriptionBytes+1)}}}, 1},
+		{"image", Image{MediaID: "m"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncationWarnings(tt.message); len(got) != tt.want {
+				t.Errorf("TruncationWarnings() = %v, want %d warnings", got, tt.want)
+			}
+		})
+	}
+}
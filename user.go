@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// userIDCache 邮箱、手机号到 userid 的缓存，成员的 userid 创建后不可修改，缓存不过期
+type userIDCache struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+// emailTypeCorp 企业邮箱，user/get_userid_by_email 的 email_type 参数
+const emailTypeCorp = 1
+
+// ResolveUserIDByEmail 根据成员的企业邮箱查询 userid，优先使用缓存
+func (n *Notify) ResolveUserIDByEmail(email string) (string, error) {
+	if email == "" {
+		return "", errors.New("email can not be empty")
+	}
+	return n.resolveUserID("email:"+email, "user/get_userid_by_email", map[string]interface{}{"email": email, "email_type": emailTypeCorp})
+}
+
+// ResolveUserIDByMobile 根据成员的手机号查询 userid，优先使用缓存
+func (n *Notify) ResolveUserIDByMobile(mobile string) (string, error) {
+	if mobile == "" {
+		return "", errors.New("mobile can not be empty")
+	}
+	return n.resolveUserID("mobile:"+mobile, "user/getuserid", map[string]interface{}{"mobile": mobile})
+}
+
+// resolveUserID 调用接口 path 查询 userid 并以 key 缓存
+func (n *Notify) resolveUserID(key, path string, body map[string]interface{}) (string, error) {
+	n.users.mu.Lock()
+	id, ok := n.users.ids[key]
+	n.users.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var result struct {
+		UserID string `json:"userid"`
+	}
+	if err := n.callAPI(http.MethodPost, path, nil, body, &result); err != nil {
+		return "", err
+	}
+
+	n.users.mu.Lock()
+	if n.users.ids == nil {
+		n.users.ids = make(map[string]string)
+	}
+	n.users.ids[key] = result.UserID
+	n.users.mu.Unlock()
+	return result.UserID, nil
+}
+
+// EmailReceiver 根据成员的企业邮箱构造消息接收者
+func (n *Notify) EmailReceiver(emails ...string) (MessageReceiver, error) {
+	return n.userReceiver(emails, n.ResolveUserIDByEmail)
+}
+
+// MobileReceiver 根据成员的手机号构造消息接收者
+func (n *Notify) MobileReceiver(mobiles ...string) (MessageReceiver, error) {
+	return n.userReceiver(mobiles, n.ResolveUserIDByMobile)
+}
+
+// userReceiver 使用 resolve 将 keys 逐个转换为 userid 并构造消息接收者
+func (n *Notify) userReceiver(keys []string, resolve func(string) (string, error)) (MessageReceiver, error) {
+	var receiver MessageReceiver
+	if len(keys) == 0 {
+		return receiver, errors.New("receivers can not be empty")
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		id, err := resolve(key)
+		if err != nil {
+			return receiver, err
+		}
+		ids = append(ids, id)
+	}
+	receiver.ToUser = strings.Join(ids, "|")
+	return receiver, nil
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNotify_EmailReceiver(t *testing.T) {
+	var calls int32
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var body struct {
+			Email  string `json:"email"`
+			Mobile string `json:"mobile"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.URL.Path == "/user/get_userid_by_email" && body.Email == "alice@example.com":
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","userid":"alice"}`))
+		case r.URL.Path == "/user/getuserid" && body.Mobile == "13800000000":
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","userid":"bob"}`))
+		default:
+			_, _ = w.Write([]byte(`{"errcode":46004,"errmsg":"user no exist"}`))
+		}
+	})
+
+	got, err := n.EmailReceiver("alice@example.com")
+	if err != nil {
+		t.Fatalf("EmailReceiver() error = %v, want no error", err)
+	}
+	if got.ToUser != "alice" {
+		t.Errorf("EmailReceiver() ToUser = %v, want %v", got.ToUser, "alice")
+	}
+	if _, err = n.ResolveUserIDByEmail("alice@example.com"); err != nil || calls != 1 {
+		t.Errorf("ResolveUserIDByEmail() error = %v after %d calls, want cached result", err, calls)
+	}
+
+	if got, err = n.MobileReceiver("13800000000"); err != nil || got.ToUser != "bob" {
+		t.Errorf("MobileReceiver() = %v, %v, want bob", got.ToUser, err)
+	}
+	if _, err = n.EmailReceiver("nobody@example.com"); err == nil {
+		t.Errorf("EmailReceiver() unknown email want error")
+	}
+	if _, err = n.EmailReceiver(); err == nil {
+		t.Errorf("EmailReceiver() with no emails want error")
+	}
+}
@@ -0,0 +1,22 @@
+package notify
+
+import "net/http"
+
+// version 包的版本号，发布时更新
+const version = "v1.4.0"
+
+// userAgent 请求企业微信接口时使用的 User-Agent
+const userAgent = "ldLirn-notify/" + version
+
+// Version 返回包的版本号
+func Version() string {
+	return version
+}
+
+// do 设置 User-Agent 后使用 client 发送请求
+func (n *Notify) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	return client.Do(req)
+}
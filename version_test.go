@@ -0,0 +1,22 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNotify_UserAgent(t *testing.T) {
+	var got string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	if _, err := n.Send(MessageReceiver{ToUser: "@all"}, Text{Content: "hi"}, nil); err != nil {
+		t.Fatalf("Send() error = %v, want no error", err)
+	}
+	if !strings.HasSuffix(got, "/"+Version()) {
+		t.Errorf("Send() User-Agent = %q, want suffix %q", got, "/"+Version())
+	}
+}
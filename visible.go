@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// VisibleUserCount 返回应用可见范围内的成员数，即发送给 @all 时的接收人数，用于记录全员消息的覆盖范围。
+// 通过 agent/get 获取可见范围，可见范围中的部门（含子部门）及标签逐个查询成员后去重计数，
+// 需要应用具有通讯录读取权限，部门及标签较多时请求次数较多，建议缓存结果
+func (n *Notify) VisibleUserCount() (int, error) {
+	if err := n.requireAgent(); err != nil {
+		return 0, err
+	}
+
+	var agent struct {
+		AllowUserInfos struct {
+			User []struct {
+				UserID string `json:"userid"`
+			} `json:"user"`
+		} `json:"allow_userinfos"`
+		AllowPartys struct {
+			PartyID []int64 `json:"partyid"`
+		} `json:"allow_partys"`
+		AllowTags struct {
+			TagID []int64 `json:"tagid"`
+		} `json:"allow_tags"`
+	}
+	query := url.Values{}
+	query.Set("agentid", strconv.FormatInt(n.agentID, 10))
+	if err := n.callAPI(http.MethodGet, "agent/get", query, nil, &agent); err != nil {
+		return 0, err
+	}
+
+	users := make(map[string]struct{})
+	for _, u := range agent.AllowUserInfos.User {
+		users[u.UserID] = struct{}{}
+	}
+	parties := agent.AllowPartys.PartyID
+	for _, tagID := range agent.AllowTags.TagID {
+		tagUsers, tagParties, err := n.tagMembers(tagID)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range tagUsers {
+			users[id] = struct{}{}
+		}
+		parties = append(parties, tagParties...)
+	}
+
+	visited := make(map[int64]bool, len(parties))
+	for _, partyID := range parties {
+		if visited[partyID] {
+			continue
+		}
+		visited[partyID] = true
+		ids, err := n.departmentUsers(partyID)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range ids {
+			users[id] = struct{}{}
+		}
+	}
+	return len(users), nil
+}
+
+// departmentUsers 返回部门及其子部门的全部成员 userid
+func (n *Notify) departmentUsers(partyID int64) ([]string, error) {
+	var result struct {
+		UserList []struct {
+			UserID string `json:"userid"`
+		} `json:"userlist"`
+	}
+	query := url.Values{}
+	query.Set("department_id", strconv.FormatInt(partyID, 10))
+	query.Set("fetch_child", "1")
+	if err := n.callAPI(http.MethodGet, "user/simplelist", query, nil, &result); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(result.UserList))
+	for _, u := range result.UserList {
+		ids = append(ids, u.UserID)
+	}
+	return ids, nil
+}
+
+// tagMembers 返回标签中的成员 userid 及部门 id
+func (n *Notify) tagMembers(tagID int64) ([]string, []int64, error) {
+	var result struct {
+		UserList []struct {
+			UserID string `json:"userid"`
+		} `json:"userlist"`
+		PartyList []int64 `json:"partylist"`
+	}
+	query := url.Values{}
+	query.Set("tagid", strconv.FormatInt(tagID, 10))
+	if err := n.callAPI(http.MethodGet, "tag/get", query, nil, &result); err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, 0, len(result.UserList))
+	for _, u := range result.UserList {
+		ids = append(ids, u.UserID)
+	}
+	return ids, result.PartyList, nil
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNotify_VisibleUserCount(t *testing.T) {
+	var paths []string
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.Query().Get("department_id")+r.URL.Query().Get("tagid"))
+		switch r.URL.Path {
+		case "/agent/get":
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","allow_userinfos":{"user":[{"userid":"alice"},{"userid":"bob"}]},"allow_partys":{"partyid":[2]},"allow_tags":{"tagid":[7]}}`))
+		case "/tag/get":
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","userlist":[{"userid":"carol"}],"partylist":[2,3]}`))
+		case "/user/simplelist":
+			if r.URL.Query().Get("department_id") == "2" {
+				_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","userlist":[{"userid":"bob"},{"userid":"dave"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok","userlist":[{"userid":"erin"}]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+
+	count, err := n.VisibleUserCount()
+	if err != nil {
+		t.Fatalf("VisibleUserCount() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("VisibleUserCount() = %d, want 5", count)
+	}
+	if len(paths) != 4 {
+		t.Errorf("requests = %v, want each department queried once", paths)
+	}
+}
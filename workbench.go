@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WorkbenchData 工作台自定义展示数据，支持 WorkbenchKeyData、WorkbenchImage、WorkbenchList、WorkbenchWebview
+//
+// 接口文档见：https://developer.work.weixin.qq.com/document/path/92535
+type WorkbenchData interface {
+	workbenchKey() string
+}
+
+// WorkbenchKeyData 关键数据型
+type WorkbenchKeyData struct {
+	Items []WorkbenchKeyDataItem `json:"items"` // 关键数据列表，不超过4个
+}
+
+func (t WorkbenchKeyData) workbenchKey() string {
+	return "keydata"
+}
+
+// WorkbenchKeyDataItem 关键数据项
+type WorkbenchKeyDataItem struct {
+	Key      string `json:"key,omitempty"`      // 非必填。关键数据名称，不超过12个字
+	Data     string `json:"data"`               // 关键数据，不超过12个字
+	JumpURL  string `json:"jump_url,omitempty"` // 非必填。点击跳转的url，与 PagePath 同时设置时以 PagePath 为准
+	PagePath string `json:"pagepath,omitempty"` // 非必填。点击跳转的小程序页面，仅小程序应用可用
+}
+
+// WorkbenchImage 图片型
+type WorkbenchImage struct {
+	URL      string `json:"url"`                // 图片的url，建议宽高比 4:1
+	JumpURL  string `json:"jump_url,omitempty"` // 非必填。点击跳转的url
+	PagePath string `json:"pagepath,omitempty"` // 非必填。点击跳转的小程序页面
+}
+
+func (t WorkbenchImage) workbenchKey() string {
+	return "image"
+}
+
+// WorkbenchList 列表型
+type WorkbenchList struct {
+	Items []WorkbenchListItem `json:"items"` // 列表项，不超过3个
+}
+
+func (t WorkbenchList) workbenchKey() string {
+	return "list"
+}
+
+// WorkbenchListItem 列表项
+type WorkbenchListItem struct {
+	Title    string `json:"title"`              // 列表显示的文字，不超过128个字节
+	JumpURL  string `json:"jump_url,omitempty"` // 非必填。点击跳转的url
+	PagePath string `json:"pagepath,omitempty"` // 非必填。点击跳转的小程序页面
+}
+
+// WorkbenchWebview webview 型
+type WorkbenchWebview struct {
+	URL       string `json:"url"`                  // 渲染展示的url
+	JumpURL   string `json:"jump_url,omitempty"`   // 非必填。点击跳转的url
+	PagePath  string `json:"pagepath,omitempty"`   // 非必填。点击跳转的小程序页面
+	Height    string `json:"height,omitempty"`     // 非必填。高度，single_row 或 double_row，默认 single_row
+	HideTitle bool   `json:"hide_title,omitempty"` // 非必填。是否隐藏应用名称标题
+}
+
+func (t WorkbenchWebview) workbenchKey() string {
+	return "webview"
+}
+
+// SetWorkbenchTemplate 设置应用在工作台展示的模版，replaceUserData 为 true 时覆盖已设置的用户数据
+func (n *Notify) SetWorkbenchTemplate(data WorkbenchData, replaceUserData bool) error {
+	if data == nil {
+		return errors.New("workbench data can not be nil")
+	}
+	if err := n.requireAgent(); err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"agentid":           n.agentID,
+		"type":              data.workbenchKey(),
+		data.workbenchKey(): data,
+		"replace_user_data": replaceUserData,
+	}
+	return n.callAPI(http.MethodPost, "agent/set_workbench_template", nil, body, nil)
+}
+
+// SetWorkbenchData 设置指定成员在工作台展示的数据，类型需与已设置的模版一致
+func (n *Notify) SetWorkbenchData(userID string, data WorkbenchData) error {
+	if data == nil {
+		return errors.New("workbench data can not be nil")
+	}
+	if err := n.requireAgent(); err != nil {
+		return err
+	}
+	if userID == "" {
+		return errors.New("workbench user id can not be empty")
+	}
+	body := map[string]interface{}{
+		"agentid":           n.agentID,
+		"userid":            userID,
+		"type":              data.workbenchKey(),
+		data.workbenchKey(): data,
+	}
+	return n.callAPI(http.MethodPost, "agent/set_workbench_data", nil, body, nil)
+}
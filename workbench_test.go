@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNotify_SetWorkbenchTemplate(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	tests := []struct {
+		data    WorkbenchData
+		replace bool
+		key     string
+		want    interface{}
+	}{
+		{
+			data: WorkbenchKeyData{Items: []WorkbenchKeyDataItem{{Key: "待审批", Data: "2", JumpURL: "https://example.com"}}},
+			key:  "keydata",
+			want: map[string]interface{}{"items": []interface{}{map[string]interface{}{"key": "待审批", "data": "2", "jump_url": "https://example.com"}}},
+		},
+		{
+			data:    WorkbenchImage{URL: "https://example.com/a.png"},
+			replace: true,
+			key:     "image",
+			want:    map[string]interface{}{"url": "https://example.com/a.png"},
+		},
+		{
+			data: WorkbenchList{Items: []WorkbenchListItem{{Title: "周报", PagePath: "pages/index"}}},
+			key:  "list",
+			want: map[string]interface{}{"items": []interface{}{map[string]interface{}{"title": "周报", "pagepath": "pages/index"}}},
+		},
+		{
+			data: WorkbenchWebview{URL: "https://example.com", Height: "double_row", HideTitle: true},
+			key:  "webview",
+			want: map[string]interface{}{"url": "https://example.com", "height": "double_row", "hide_title": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := n.SetWorkbenchTemplate(tt.data, tt.replace); err != nil {
+				t.Fatalf("SetWorkbenchTemplate() error = %v", err)
+			}
+			if path != "/agent/set_workbench_template" {
+				t.Errorf("request path = %s, want /agent/set_workbench_template", path)
+			}
+			if body["type"] != tt.key || body["agentid"] != float64(1) || body["replace_user_data"] != tt.replace {
+				t.Errorf("request body = %v, want type %s and replace_user_data %v", body, tt.key, tt.replace)
+			}
+			if !reflect.DeepEqual(body[tt.key], tt.want) {
+				t.Errorf("request body[%s] = %v, want %v", tt.key, body[tt.key], tt.want)
+			}
+		})
+	}
+}
+
+func TestNotify_SetWorkbenchData(t *testing.T) {
+	var body map[string]interface{}
+	n := newTestNotify(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agent/set_workbench_data" {
+			t.Errorf("request path = %s, want /agent/set_workbench_data", r.URL.Path)
+		}
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["userid"] == "nobody" {
+			_, _ = w.Write([]byte(`{"errcode":60111,"errmsg":"invalid userid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	})
+
+	data := WorkbenchKeyData{Items: []WorkbenchKeyDataItem{{Data: "8"}}}
+	if err := n.SetWorkbenchData("zhangsan", data); err != nil {
+		t.Fatalf("SetWorkbenchData() error = %v", err)
+	}
+	want := map[string]interface{}{"items": []interface{}{map[string]interface{}{"data": "8"}}}
+	if body["userid"] != "zhangsan" || body["type"] != "keydata" || !reflect.DeepEqual(body["keydata"], want) {
+		t.Errorf("request body = %v, want keydata for zhangsan", body)
+	}
+	if _, ok := body["replace_user_data"]; ok {
+		t.Errorf("request body = %v, want no replace_user_data", body)
+	}
+
+	var apiErr *APIError
+	if err := n.SetWorkbenchData("nobody", data); !errors.As(err, &apiErr) || apiErr.Code != 60111 {
+		t.Errorf("SetWorkbenchData() error = %v, want APIError 60111", err)
+	}
+	if err := n.SetWorkbenchData("", data); err == nil {
+		t.Errorf("SetWorkbenchData() want error for empty user id")
+	}
+	if err := n.SetWorkbenchData("zhangsan", nil); err == nil {
+		t.Errorf("SetWorkbenchData() want error for nil data")
+	}
+	if err := n.SetWorkbenchTemplate(nil, false); err == nil {
+		t.Errorf("SetWorkbenchTemplate() want error for nil data")
+	}
+}